@@ -0,0 +1,113 @@
+package hyper
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaRange is a single element of an Accept header.
+type MediaRange struct {
+	Type       string
+	Subtype    string
+	Parameters map[string]string
+	Q          float64
+}
+
+// ParseAccept parses the value of an Accept header into its media ranges ordered by
+// descending quality. Ranges with equal quality keep the order of the header.
+func ParseAccept(header string) []MediaRange {
+	var mrs []MediaRange
+	for _, raw := range strings.Split(header, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		parts := strings.Split(raw, ";")
+		mr := MediaRange{Q: 1}
+		typ := strings.ToLower(strings.TrimSpace(parts[0]))
+		if i := strings.Index(typ, "/"); i >= 0 {
+			mr.Type, mr.Subtype = typ[:i], typ[i+1:]
+		} else {
+			mr.Type, mr.Subtype = typ, "*"
+		}
+		for _, p := range parts[1:] {
+			kv := strings.SplitN(p, "=", 2)
+			k := strings.ToLower(strings.TrimSpace(kv[0]))
+			v := ""
+			if len(kv) == 2 {
+				v = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+			}
+			if k == "q" {
+				if q, err := strconv.ParseFloat(v, 64); err == nil && q >= 0 && q <= 1 {
+					mr.Q = q
+				}
+				continue
+			}
+			if mr.Parameters == nil {
+				mr.Parameters = map[string]string{}
+			}
+			mr.Parameters[k] = v
+		}
+		mrs = append(mrs, mr)
+	}
+	sort.SliceStable(mrs, func(i, j int) bool {
+		return mrs[i].Q > mrs[j].Q
+	})
+	return mrs
+}
+
+// Match reports whether the media type is covered by this range and how specific the match is.
+func (mr MediaRange) Match(mediaType string) (int, bool) {
+	typ, sub := splitMediaType(mediaType)
+	switch {
+	case mr.Type == "*" && mr.Subtype == "*":
+		return 0, true
+	case mr.Type == typ && mr.Subtype == "*":
+		return 1, true
+	case mr.Type == typ && mr.Subtype == sub:
+		return 2 + len(mr.Parameters), true
+	}
+	return 0, false
+}
+
+// AcceptQuality returns the quality the media ranges assign to the media type.
+// An empty set of ranges accepts everything.
+func AcceptQuality(mrs []MediaRange, mediaType string) float64 {
+	if len(mrs) == 0 {
+		return 1
+	}
+	q, specificity := 0.0, -1
+	for _, mr := range mrs {
+		if s, ok := mr.Match(mediaType); ok && s > specificity {
+			q, specificity = mr.Q, s
+		}
+	}
+	return q
+}
+
+// PrefersHTML reports whether the request prefers text/html over hyper-items.
+func PrefersHTML(r *http.Request) bool {
+	mrs := ParseAccept(r.Header.Get(HeaderAccept))
+	if len(mrs) == 0 {
+		return false
+	}
+	html := AcceptQuality(mrs, ContentTypeHTML)
+	item := AcceptQuality(mrs, ContentTypeHyperItem)
+	if json := AcceptQuality(mrs, ContentTypeJSON); json > item {
+		item = json
+	}
+	return html > 0 && html > item
+}
+
+func splitMediaType(mediaType string) (string, string) {
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if i := strings.Index(mediaType, "/"); i >= 0 {
+		return mediaType[:i], mediaType[i+1:]
+	}
+	return mediaType, ""
+}
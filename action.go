@@ -15,6 +15,7 @@ type Action struct {
 	OK          string     `json:"ok,omitempty"`
 	Cancel      string     `json:"cancel,omitempty"`
 	Reset       string     `json:"reset,omitempty"`
+	Idempotent  bool       `json:"idempotent,omitempty"`
 }
 
 // Actions .
@@ -76,6 +77,7 @@ func ActionContextEquals(ctx string) func(Action) bool {
 }
 
 const (
+	MethodGET    = "GET"
 	MethodPOST   = "POST"
 	MethodPATCH  = "PATCH"
 	MethodDELETE = "DELETE"
@@ -0,0 +1,259 @@
+package hyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TagName is the struct tag used to name the argument a field is bound to.
+const TagName = "hyper"
+
+// Bind maps the arguments of the Command onto the fields of the struct pointed to by v.
+func (c Command) Bind(v interface{}) error {
+	return c.Arguments.Bind(v)
+}
+
+// Bind maps the arguments onto the fields of the struct pointed to by v.
+// Fields are matched by the name in their `hyper:"name"` tag or by their
+// field name. Fields without a corresponding argument are left untouched.
+// All conversion failures are reported as Errors.
+func (a Arguments) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: expected non-nil pointer to struct, got %T", v)
+	}
+	errs := bindStruct(rv.Elem(), a, "")
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var (
+	typeFileHeader  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	typeFileHeaders = reflect.TypeOf([]*multipart.FileHeader(nil))
+	typeTime        = reflect.TypeOf(time.Time{})
+	typeBytes       = reflect.TypeOf([]byte(nil))
+)
+
+func bindStruct(sv reflect.Value, args Arguments, prefix string) Errors {
+	var errs Errors
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		fv := sv.Field(i)
+		if f.Anonymous && f.Tag.Get(TagName) == "" && indirectType(f.Type).Kind() == reflect.Struct {
+			// embedded structs share the arguments of their parent
+			if fv.Kind() == reflect.Ptr {
+				if !fv.CanSet() {
+					continue
+				}
+				if fv.IsNil() {
+					fv.Set(reflect.New(f.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			errs = append(errs, bindStruct(fv, args, prefix)...)
+			continue
+		}
+		path := prefix + name
+		v, ok := args[name]
+		if !ok {
+			sub := args.prefixed(name + ".")
+			if len(sub) == 0 || indirectType(f.Type).Kind() != reflect.Struct {
+				continue
+			}
+			v = map[string]interface{}(sub)
+		}
+		errs = append(errs, bindValue(fv, v, path)...)
+	}
+	return errs
+}
+
+// prefixed returns the arguments whose name starts with prefix with the prefix removed.
+func (a Arguments) prefixed(prefix string) Arguments {
+	var res Arguments
+	for k, v := range a {
+		if strings.HasPrefix(k, prefix) {
+			if res == nil {
+				res = Arguments{}
+			}
+			res[strings.TrimPrefix(k, prefix)] = v
+		}
+	}
+	return res
+}
+
+func bindValue(fv reflect.Value, v interface{}, path string) Errors {
+	if v == nil {
+		return nil
+	}
+	ft := fv.Type()
+	switch ft {
+	case typeFileHeader:
+		switch v := v.(type) {
+		case *multipart.FileHeader:
+			fv.Set(reflect.ValueOf(v))
+			return nil
+		case []*multipart.FileHeader:
+			if len(v) == 1 {
+				fv.Set(reflect.ValueOf(v[0]))
+				return nil
+			}
+		}
+		return bindError(path, v, ft)
+	case typeFileHeaders:
+		switch v := v.(type) {
+		case *multipart.FileHeader:
+			fv.Set(reflect.ValueOf([]*multipart.FileHeader{v}))
+			return nil
+		case []*multipart.FileHeader:
+			fv.Set(reflect.ValueOf(v))
+			return nil
+		}
+		return bindError(path, v, ft)
+	case typeBytes:
+		bs := Arguments{"v": v}.Bytes("v")
+		if bs == nil {
+			return bindError(path, v, ft)
+		}
+		fv.SetBytes(bs)
+		return nil
+	case typeTime:
+		s, ok := v.(string)
+		if !ok {
+			return bindError(path, v, ft)
+		}
+		t, err := parseAnyTime(s)
+		if err != nil {
+			return bindError(path, v, ft)
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch ft.Kind() {
+	case reflect.Ptr:
+		pv := reflect.New(ft.Elem())
+		errs := bindValue(pv.Elem(), v, path)
+		if len(errs) == 0 {
+			fv.Set(pv)
+		}
+		return errs
+	case reflect.String:
+		s, ok := toString(v)
+		if !ok {
+			return bindError(path, v, ft)
+		}
+		fv.SetString(s)
+	case reflect.Bool:
+		b, ok := toBool(v)
+		if !ok {
+			return bindError(path, v, ft)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(v)
+		if !ok || fv.OverflowInt(i) {
+			return bindError(path, v, ft)
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := toInt64(v)
+		if !ok || i < 0 || fv.OverflowUint(uint64(i)) {
+			return bindError(path, v, ft)
+		}
+		fv.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(v)
+		if !ok || math.IsNaN(f) || fv.OverflowFloat(f) {
+			return bindError(path, v, ft)
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		vs, ok := multipleValues(v)
+		if !ok {
+			vs = []interface{}{v}
+		}
+		var errs Errors
+		sv := reflect.MakeSlice(ft, len(vs), len(vs))
+		for i, v := range vs {
+			errs = append(errs, bindValue(sv.Index(i), v, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		fv.Set(sv)
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return bindError(path, v, ft)
+		}
+		return bindStruct(fv, Arguments(m), path+".")
+	default:
+		// maps, interfaces and everything else take the JSON route
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return bindError(path, v, ft)
+		}
+		pv := reflect.New(ft)
+		if err := json.Unmarshal(bs, pv.Interface()); err != nil {
+			return bindError(path, v, ft)
+		}
+		fv.Set(pv.Elem())
+	}
+	return nil
+}
+
+func bindError(path string, v interface{}, t reflect.Type) Errors {
+	return Errors{{
+		Name:    path,
+		Code:    ErrorCodeType,
+		Message: fmt.Sprintf("%s: cannot convert %#v to %s", path, v, t),
+	}}
+}
+
+// parseAnyTime parses s as datetime, date, month, week or time (in that order).
+func parseAnyTime(s string) (time.Time, error) {
+	for _, typ := range []string{TypeDatetime, TypeDate, TypeMonth, TypeWeek, TypeTime} {
+		if t, err := parseTimeValue(typ, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %q", s)
+}
+
+// fieldName returns the argument name of a struct field and false if the field is to be skipped.
+func fieldName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" && (!f.Anonymous || indirectType(f.Type).Kind() != reflect.Struct || f.Tag.Get(TagName) != "") {
+		// unexported, except for embedded structs sharing their exported fields
+		return "", false
+	}
+	tag := f.Tag.Get(TagName)
+	if tag == "-" {
+		return "", false
+	}
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" {
+		return f.Name, true
+	}
+	return tag, true
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,121 @@
+package hyper
+
+import (
+	"mime/multipart"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCommandBind(t *testing.T) {
+	type address struct {
+		Street string `hyper:"street"`
+		Zip    int    `hyper:"zip"`
+	}
+	type base struct {
+		ID string `hyper:"id"`
+	}
+	type text string
+	type target struct {
+		base
+		text
+		Name     string                `hyper:"name"`
+		Age      int                   `hyper:"age"`
+		Score    float64               `hyper:"score"`
+		Active   bool                  `hyper:"active"`
+		Tags     []string              `hyper:"tags"`
+		Numbers  []int64               `hyper:"numbers"`
+		Born     time.Time             `hyper:"born"`
+		Nick     *string               `hyper:"nick"`
+		Home     address               `hyper:"home"`
+		Work     address               `hyper:"work"`
+		File     *multipart.FileHeader `hyper:"file"`
+		Data     []byte                `hyper:"data"`
+		Ignored  string                `hyper:"-"`
+		Untagged string
+	}
+
+	fh := &multipart.FileHeader{Filename: "foo.txt"}
+	c := MakeCommand()
+	c.Arguments = Arguments{
+		"id":          "42",
+		"name":        "Jane",
+		"age":         "33",
+		"score":       1.5,
+		"active":      "on",
+		"tags":        []string{"a", "b"},
+		"numbers":     []interface{}{1.0, "2"},
+		"born":        "2000-02-29",
+		"nick":        "J",
+		"home":        map[string]interface{}{"street": "Main St", "zip": 12345.0},
+		"work.street": "Side St",
+		"work.zip":    "54321",
+		"file":        fh,
+		"data":        "data:text/plain;base64,SGVsbG8sIFdvcmxkIQ%3D%3D",
+		"Ignored":     "x",
+		"Untagged":    "y",
+		"text":        "x",
+	}
+	got := target{}
+	if err := c.Bind(&got); err != nil {
+		t.Fatalf("expected no error: %v", err)
+	}
+	nick := "J"
+	want := target{
+		base:     base{ID: "42"},
+		Name:     "Jane",
+		Age:      33,
+		Score:    1.5,
+		Active:   true,
+		Tags:     []string{"a", "b"},
+		Numbers:  []int64{1, 2},
+		Born:     time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC),
+		Nick:     &nick,
+		Home:     address{Street: "Main St", Zip: 12345},
+		Work:     address{Street: "Side St", Zip: 54321},
+		File:     fh,
+		Data:     []byte("Hello, World!"),
+		Untagged: "y",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant: %#v\ngot: %#v\n", want, got)
+	}
+}
+
+func TestCommandBindErrors(t *testing.T) {
+	type target struct {
+		Age     int8      `hyper:"age"`
+		Count   uint      `hyper:"count"`
+		Active  bool      `hyper:"active"`
+		Numbers []int     `hyper:"numbers"`
+		Born    time.Time `hyper:"born"`
+	}
+	c := MakeCommand()
+	c.Arguments = Arguments{
+		"age":     "300",
+		"count":   "-1",
+		"active":  "maybe",
+		"numbers": []string{"1", "x"},
+		"born":    "yesterday",
+	}
+	err := c.Bind(&target{})
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got: %#v", err)
+	}
+	var names []string
+	for _, e := range errs {
+		if e.Code != ErrorCodeType {
+			t.Errorf("want: %s, got: %s", ErrorCodeType, e.Code)
+		}
+		names = append(names, e.Name)
+	}
+	want := []string{"age", "count", "active", "numbers[1]", "born"}
+	if !reflect.DeepEqual(want, names) {
+		t.Errorf("want: %v, got: %v", want, names)
+	}
+
+	if err := c.Bind(target{}); err == nil {
+		t.Errorf("expected an error for non-pointer")
+	}
+}
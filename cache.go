@@ -0,0 +1,361 @@
+package hyper
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry is a response stored in a CacheStore.
+type CacheEntry struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Data       []byte      `json:"data"`
+	// Vary holds the values of the request headers named by the Vary header of the response.
+	Vary   map[string]string `json:"vary,omitempty"`
+	Stored time.Time         `json:"stored"`
+}
+
+// CacheStore stores responses for the Client. Implementations must be safe for concurrent use.
+type CacheStore interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, e CacheEntry)
+	Delete(key string)
+}
+
+// WithCache lets the Client cache the responses of GET requests in s.
+//
+// Responses are keyed by URL, Accept, Accept-Language and the credentials (Authorization and Cookie),
+// so responses are never shared between users, and are only stored if
+// they carry a validator (ETag or Last-Modified) or a freshness lifetime (Cache-Control max-age or Expires).
+// Fresh responses are served from the cache, stale ones are revalidated with If-None-Match and
+// If-Modified-Since. Cache-Control no-store and no-cache are honored for requests and responses,
+// and responses are only reused if the request headers named by their Vary header match.
+func WithCache(s CacheStore) ClientOption {
+	return func(c *Client) {
+		c.cache = s
+	}
+}
+
+func cacheKey(req *http.Request) string {
+	key := req.URL.String()
+	for _, h := range []string{HeaderAccept, HeaderAcceptLanguage, HeaderAuthorization, HeaderCookie} {
+		key += "\n" + strings.Join(req.Header[h], ",")
+	}
+	return key
+}
+
+// doCached sends a GET request through the cache.
+func (c *Client) doCached(req *http.Request) (*http.Response, error) {
+	reqCC := parseCacheControl(req.Header.Get(HeaderCacheControl))
+	if _, ok := reqCC["no-store"]; ok {
+		return c.send(req)
+	}
+	key := cacheKey(req)
+	e, cached := c.cache.Get(key)
+	if cached && !e.matches(req) {
+		cached = false
+	}
+	if cached {
+		if _, noCache := reqCC["no-cache"]; !noCache && e.fresh(time.Now()) {
+			return e.response(req), nil
+		}
+		if etag := e.Header.Get(HeaderETag); etag != "" {
+			req.Header.Set(HeaderIfNoneMatch, etag)
+		}
+		if lm := e.Header.Get(HeaderLastModified); lm != "" {
+			req.Header.Set(HeaderIfModifiedSince, lm)
+		}
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case cached && resp.StatusCode == http.StatusNotModified:
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		e.Header = cloneHeader(e.Header)
+		for k, vs := range resp.Header {
+			e.Header[k] = vs
+		}
+		e.Stored = time.Now()
+		c.cache.Set(key, e)
+		return e.response(req), nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		c.cache.Delete(key)
+		return resp, nil
+	case resp.StatusCode != http.StatusOK || !storable(resp.Header):
+		return resp, nil
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read: %v", err)
+	}
+	c.cache.Set(key, CacheEntry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Data:       data,
+		Vary:       varyValues(req, resp.Header),
+		Stored:     time.Now(),
+	})
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+// matches reports whether the request headers named by Vary match those of the stored request.
+func (e CacheEntry) matches(req *http.Request) bool {
+	for name, v := range e.Vary {
+		if req.Header.Get(name) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// fresh reports whether the entry may be served without revalidation.
+func (e CacheEntry) fresh(now time.Time) bool {
+	cc := parseCacheControl(e.Header.Get(HeaderCacheControl))
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+	lifetime, ok := freshnessLifetime(e.Header)
+	if !ok {
+		return false
+	}
+	age := now.Sub(e.Stored)
+	if s, err := strconv.Atoi(e.Header.Get(HeaderAge)); err == nil {
+		age += time.Duration(s) * time.Second
+	}
+	return age < lifetime
+}
+
+func (e CacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cloneHeader(e.Header),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Data)),
+		ContentLength: int64(len(e.Data)),
+		Request:       req,
+	}
+}
+
+func freshnessLifetime(h http.Header) (time.Duration, bool) {
+	cc := parseCacheControl(h.Get(HeaderCacheControl))
+	if v, ok := cc["max-age"]; ok {
+		s, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	if v := h.Get(HeaderExpires); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0, false
+		}
+		date, err := http.ParseTime(h.Get("Date"))
+		if err != nil {
+			date = time.Now()
+		}
+		return expires.Sub(date), true
+	}
+	return 0, false
+}
+
+func storable(h http.Header) bool {
+	cc := parseCacheControl(h.Get(HeaderCacheControl))
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if strings.TrimSpace(h.Get(HeaderVary)) == "*" {
+		return false
+	}
+	if h.Get(HeaderETag) != "" || h.Get(HeaderLastModified) != "" {
+		return true
+	}
+	lifetime, ok := freshnessLifetime(h)
+	return ok && lifetime > 0
+}
+
+func varyValues(req *http.Request, h http.Header) map[string]string {
+	var vs map[string]string
+	for _, v := range h[HeaderVary] {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if vs == nil {
+				vs = map[string]string{}
+			}
+			vs[name] = req.Header.Get(name)
+		}
+	}
+	return vs
+}
+
+// parseCacheControl parses the directives of a Cache-Control header.
+func parseCacheControl(v string) map[string]string {
+	cc := map[string]string{}
+	for _, d := range strings.Split(v, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		if i := strings.Index(d, "="); i >= 0 {
+			cc[strings.ToLower(d[:i])] = strings.Trim(d[i+1:], `"`)
+		} else {
+			cc[strings.ToLower(d)] = ""
+		}
+	}
+	return cc
+}
+
+func cloneHeader(h http.Header) http.Header {
+	res := make(http.Header, len(h))
+	for k, vs := range h {
+		res[k] = append([]string(nil), vs...)
+	}
+	return res
+}
+
+// NewMemoryCache creates an in-memory CacheStore holding at most size entries.
+// The least recently used entries are evicted first. A size <= 0 means unlimited.
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{
+		size:    size,
+		ll:      list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// MemoryCache is an in-memory LRU CacheStore.
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+func (mc *MemoryCache) Get(key string) (CacheEntry, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	el, ok := mc.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	mc.ll.MoveToFront(el)
+	return el.Value.(*memoryCacheItem).entry, true
+}
+
+func (mc *MemoryCache) Set(key string, e CacheEntry) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if el, ok := mc.entries[key]; ok {
+		el.Value.(*memoryCacheItem).entry = e
+		mc.ll.MoveToFront(el)
+		return
+	}
+	mc.entries[key] = mc.ll.PushFront(&memoryCacheItem{key: key, entry: e})
+	for mc.size > 0 && mc.ll.Len() > mc.size {
+		el := mc.ll.Back()
+		mc.ll.Remove(el)
+		delete(mc.entries, el.Value.(*memoryCacheItem).key)
+	}
+}
+
+func (mc *MemoryCache) Delete(key string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if el, ok := mc.entries[key]; ok {
+		mc.ll.Remove(el)
+		delete(mc.entries, key)
+	}
+}
+
+// Len returns the number of cached entries.
+func (mc *MemoryCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.ll.Len()
+}
+
+// NewDiskCache creates a CacheStore that keeps one file per entry in dir.
+// The directory is created on demand. Entries that cannot be read or written are treated as missing.
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{dir: dir}
+}
+
+// DiskCache is an on-disk CacheStore.
+type DiskCache struct {
+	dir string
+}
+
+func (dc *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (dc *DiskCache) Get(key string) (CacheEntry, bool) {
+	bs, err := ioutil.ReadFile(dc.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	e := CacheEntry{}
+	if err := json.Unmarshal(bs, &e); err != nil {
+		return CacheEntry{}, false
+	}
+	return e, true
+}
+
+func (dc *DiskCache) Set(key string, e CacheEntry) {
+	bs, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dc.dir, 0755); err != nil {
+		return
+	}
+	f, err := ioutil.TempFile(dc.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(bs)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	if err := os.Rename(f.Name(), dc.path(key)); err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+func (dc *DiskCache) Delete(key string) {
+	os.Remove(dc.path(key))
+}
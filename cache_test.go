@@ -0,0 +1,124 @@
+package hyper
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestClientCache(t *testing.T) {
+	var hits, revalidations int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set(HeaderCacheControl, "max-age=60")
+		case "/etag":
+			w.Header().Set(HeaderCacheControl, "no-cache")
+			w.Header().Set(HeaderETag, `"v1"`)
+			if r.Header.Get(HeaderIfNoneMatch) == `"v1"` {
+				revalidations++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/vary":
+			w.Header().Set(HeaderCacheControl, "max-age=60")
+			w.Header().Set(HeaderVary, "X-Tenant")
+		case "/private":
+			w.Header().Set(HeaderCacheControl, "max-age=60")
+		case "/no-store":
+			w.Header().Set(HeaderCacheControl, "no-store")
+			w.Header().Set(HeaderETag, `"v1"`)
+		}
+		Write(w, http.StatusOK, Item{Label: r.URL.Path + "|" + r.Header.Get("X-Tenant") + r.Header.Get(HeaderAuthorization) + r.Header.Get(HeaderCookie)})
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		path          string
+		header        string
+		tenants       []string
+		hits          int
+		revalidations int
+	}{
+		{path: "/fresh", tenants: []string{"", ""}, hits: 1},
+		{path: "/etag", tenants: []string{"", "", ""}, hits: 3, revalidations: 2},
+		{path: "/vary", tenants: []string{"a", "a", "b"}, hits: 2},
+		{path: "/private", header: HeaderAuthorization, tenants: []string{"a", "a", "b", ""}, hits: 3},
+		{path: "/private", header: HeaderCookie, tenants: []string{"a", "b", "b"}, hits: 2},
+		{path: "/no-store", tenants: []string{"", ""}, hits: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			hits, revalidations = 0, 0
+			header := test.header
+			if header == "" {
+				header = "X-Tenant"
+			}
+			c := NewClient(WithCache(NewMemoryCache(10)))
+			for _, tenant := range test.tenants {
+				got, err := c.Fetch(s.URL+test.path, Header(header, tenant))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := test.path + "|" + tenant; got.Label != want {
+					t.Errorf("want: %s, got: %s", want, got.Label)
+				}
+			}
+			if hits != test.hits {
+				t.Errorf("want: %d hits, got: %d", test.hits, hits)
+			}
+			if revalidations != test.revalidations {
+				t.Errorf("want: %d revalidations, got: %d", test.revalidations, revalidations)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	mc := NewMemoryCache(2)
+	mc.Set("a", CacheEntry{Data: []byte("a")})
+	mc.Set("b", CacheEntry{Data: []byte("b")})
+	mc.Get("a")
+	mc.Set("c", CacheEntry{Data: []byte("c")})
+	if _, ok := mc.Get("b"); ok {
+		t.Errorf("want: b evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := mc.Get(key); !ok {
+			t.Errorf("want: %s cached", key)
+		}
+	}
+	if mc.Len() != 2 {
+		t.Errorf("want: %d, got: %d", 2, mc.Len())
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hyper-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dc := NewDiskCache(dir)
+	if _, ok := dc.Get("k"); ok {
+		t.Errorf("want: miss")
+	}
+	h := http.Header{}
+	h.Set(HeaderETag, `"v1"`)
+	dc.Set("k", CacheEntry{StatusCode: http.StatusOK, Header: h, Data: []byte("data")})
+	e, ok := dc.Get("k")
+	if !ok {
+		t.Fatalf("want: hit")
+	}
+	if string(e.Data) != "data" || e.Header.Get(HeaderETag) != `"v1"` {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+	dc.Delete("k")
+	if _, ok := dc.Get("k"); ok {
+		t.Errorf("want: miss after delete")
+	}
+}
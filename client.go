@@ -2,11 +2,18 @@ package hyper
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 )
 
 func AcceptLanguage(spec string) func(*http.Request) {
@@ -21,48 +28,257 @@ func Accept(typ string) func(*http.Request) {
 	}
 }
 
-func NewClient() *Client {
-	return &Client{
+// Header sets a request header.
+func Header(key string, value string) func(*http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
+// NewClient creates a Client that decodes hyper-items, HAL, Siren, Collection+JSON and JSON:API.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
 		httpClient: &http.Client{},
+		header:     http.Header{},
+	}
+	c.RegisterDecoder(ContentTypeHyperItem, DecodeHyperItem)
+	c.RegisterDecoder(ContentTypeHAL, DecodeHAL)
+	c.RegisterDecoder(ContentTypeSiren, DecodeSiren)
+	c.RegisterDecoder(ContentTypeCollectionJSON, DecodeCollectionJSON)
+	c.RegisterDecoder(ContentTypeJSONAPI, DecodeJSONAPI)
+	c.RegisterDecoder(ContentTypeJSON, DecodeHyperItem)
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 type Client struct {
 	httpClient *http.Client
+	header     http.Header
+	baseURL    string
+	cache      CacheStore
+	retry      *RetryPolicy
+	decoders   []clientDecoder
 }
 
-func (c *Client) Fetch(url string, opts ...func(*http.Request)) (Item, error) {
-	opts = append([]func(*http.Request){Accept(ContentTypeHyperItem)}, opts...)
-	_, data, err := c.FetchRaw(url, opts...)
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithHTTPClient lets the Client send its requests with hc.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout limits the time of each request including reading the response body.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Timeout = d
+		c.httpClient = &hc
+	}
+}
+
+// WithTransport lets the Client send its requests with rt.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Transport = rt
+		c.httpClient = &hc
+	}
+}
+
+// WithHeader adds a header to every request. Request options take precedence.
+func WithHeader(key string, value string) ClientOption {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithBaseURL resolves relative URLs of all requests against base.
+func WithBaseURL(base string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = base
+	}
+}
+
+// Decoder decodes an Item from a specific media type.
+type Decoder func(r io.Reader) (Item, error)
+
+// DecodeHyperItem decodes a hyper-item.
+func DecodeHyperItem(r io.Reader) (Item, error) {
 	res := Item{}
+	err := json.NewDecoder(r).Decode(&res)
+	return res, err
+}
+
+type clientDecoder struct {
+	mediaType string
+	decode    Decoder
+}
+
+// RegisterDecoder registers a Decoder for a media type. Fetch announces all registered media types
+// in its Accept header, preferring them in the order of registration.
+func (c *Client) RegisterDecoder(mediaType string, dec Decoder) {
+	typ, sub := splitMediaType(mediaType)
+	cd := clientDecoder{mediaType: typ + "/" + sub, decode: dec}
+	for i, d := range c.decoders {
+		if d.mediaType == cd.mediaType {
+			c.decoders[i] = cd
+			return
+		}
+	}
+	c.decoders = append(c.decoders, cd)
+}
+
+// accept builds an Accept header from the registered decoders.
+func (c *Client) accept() string {
+	var mrs []string
+	for i, d := range c.decoders {
+		q := 10 - i
+		switch {
+		case i == 0:
+			mrs = append(mrs, d.mediaType)
+			continue
+		case q < 1:
+			q = 1
+		}
+		mrs = append(mrs, fmt.Sprintf("%s;q=0.%d", d.mediaType, q))
+	}
+	return strings.Join(mrs, ", ")
+}
+
+// decoder returns the Decoder for a content type and defaults to hyper-items.
+func (c *Client) decoder(contentType string) Decoder {
+	typ, sub := splitMediaType(contentType)
+	for _, d := range c.decoders {
+		if d.mediaType == typ+"/"+sub {
+			return d.decode
+		}
+	}
+	return DecodeHyperItem
+}
+
+// Fetch fetches the Item at url, decoding it according to the content type of the response.
+// For responses with a status code other than 2xx an *HTTPError is returned.
+func (c *Client) Fetch(url string, opts ...func(*http.Request)) (Item, error) {
+	return c.FetchContext(context.Background(), url, opts...)
+}
+
+// FetchContext is like Fetch with a context.
+func (c *Client) FetchContext(ctx context.Context, url string, opts ...func(*http.Request)) (Item, error) {
+	opts = append([]func(*http.Request){Accept(c.accept())}, opts...)
+	header, data, err := c.FetchRawContext(ctx, url, opts...)
 	if err != nil {
-		return res, err
+		return Item{}, err
 	}
-	err = json.Unmarshal(data, &res)
+	return c.decode(header, data)
+}
+
+// decode decodes data according to the content type of the header.
+func (c *Client) decode(header http.Header, data []byte) (Item, error) {
+	res, err := c.decoder(header.Get(HeaderContentType))(bytes.NewReader(data))
 	if err != nil {
 		return res, fmt.Errorf("decode: %v", err)
 	}
 	return res, nil
 }
 
+// FetchRaw fetches the raw content at url. For responses with a status code other than 2xx
+// the header and data are returned together with an *HTTPError.
 func (c *Client) FetchRaw(url string, opts ...func(*http.Request)) (http.Header, []byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
+	return c.FetchRawContext(context.Background(), url, opts...)
+}
+
+// FetchRawContext is like FetchRaw with a context.
+func (c *Client) FetchRawContext(ctx context.Context, url string, opts ...func(*http.Request)) (http.Header, []byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, url, nil, opts)
 	if err != nil {
-		return nil, nil, fmt.Errorf("create: %v", err)
+		return nil, nil, err
 	}
-	for _, opt := range opts {
-		opt(req)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.Header, data, fmt.Errorf("read: %v", err)
 	}
-	resp, err := c.httpClient.Do(req)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.Header, data, c.httpError(resp.StatusCode, resp.Header, data)
+	}
+	return resp.Header, data, nil
+}
+
+// Follow fetches the target of the first Link of i with the given rel.
+// See FollowLink.
+func (c *Client) Follow(i Item, rel string, vars map[string]interface{}, opts ...func(*http.Request)) (Item, error) {
+	return c.FollowContext(context.Background(), i, rel, vars, opts...)
+}
+
+// FollowContext is like Follow with a context.
+func (c *Client) FollowContext(ctx context.Context, i Item, rel string, vars map[string]interface{}, opts ...func(*http.Request)) (Item, error) {
+	l, ok := i.Links.FindByRel(rel)
+	if !ok {
+		return Item{}, fmt.Errorf("follow: no link with rel %q", rel)
+	}
+	return c.FollowLinkContext(ctx, i, l, vars, opts...)
+}
+
+// FollowLink fetches the target of l. A Template is expanded with vars (RFC 6570) and
+// the result is resolved against the self link of i. The Accept and AcceptLanguage of
+// the Link are sent along unless overridden by opts.
+func (c *Client) FollowLink(i Item, l Link, vars map[string]interface{}, opts ...func(*http.Request)) (Item, error) {
+	return c.FollowLinkContext(context.Background(), i, l, vars, opts...)
+}
+
+// FollowLinkContext is like FollowLink with a context.
+func (c *Client) FollowLinkContext(ctx context.Context, i Item, l Link, vars map[string]interface{}, opts ...func(*http.Request)) (Item, error) {
+	href, err := expandLink(l, vars)
 	if err != nil {
-		return nil, nil, fmt.Errorf("do: %v", err)
+		return Item{}, fmt.Errorf("follow %s: %v", l.Rel, err)
 	}
-	defer resp.Body.Close()
-	data, err := ioutil.ReadAll(resp.Body)
-	return resp.Header, data, err
+	if self, ok := i.Links.FindByRel(RelSelf); ok {
+		if href, err = resolveHref(self.Href, href); err != nil {
+			return Item{}, fmt.Errorf("follow %s: %v", l.Rel, err)
+		}
+	}
+	var lopts []func(*http.Request)
+	if l.Accept != "" {
+		lopts = append(lopts, Accept(l.Accept))
+	}
+	if l.AcceptLanguage != "" {
+		lopts = append(lopts, AcceptLanguage(l.AcceptLanguage))
+	}
+	return c.FetchContext(ctx, href, append(lopts, opts...)...)
+}
+
+// resolveHref resolves a possibly relative href against base.
+func resolveHref(base string, href string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("parse base: %v", err)
+	}
+	h, err := url.Parse(href)
+	if err != nil {
+		return "", fmt.Errorf("parse href: %v", err)
+	}
+	return b.ResolveReference(h).String(), nil
 }
 
+// Submit submits args to the Action. Hidden Parameters of the Action (e.g. the @action parameter)
+// are sent along unless overridden by args. The body is encoded according to the Encoding of the
+// Action: ContentTypeURLEncoded, ContentTypeMultipartFormData, ContentTypeCollectionJSON (as write
+// template) or JSON (the default).
+// Nested maps are flattened into dotted names for forms; for multipart forms, io.Reader arguments
+// (like *os.File) and *multipart.FileHeader arguments are uploaded as files.
+// The Method defaults to POST. For GET and HEAD the arguments are form encoded into the query of
+// the href instead. Actions marked as Idempotent are retried by a RetryPolicy (see WithRetry).
 func (c *Client) Submit(a Action, args Arguments, opts ...func(*http.Request)) (*http.Response, error) {
+	return c.SubmitContext(context.Background(), a, args, opts...)
+}
+
+// SubmitContext is like Submit with a context.
+func (c *Client) SubmitContext(ctx context.Context, a Action, args Arguments, opts ...func(*http.Request)) (*http.Response, error) {
 	as := Arguments{}
 	for _, p := range a.Parameters {
 		if p.Type == TypeHidden {
@@ -72,24 +288,332 @@ func (c *Client) Submit(a Action, args Arguments, opts ...func(*http.Request)) (
 	for k, v := range args {
 		as[k] = v
 	}
-	buf := bytes.Buffer{}
-	err := json.NewEncoder(&buf).Encode(as)
+	method := a.Method
+	if method == "" {
+		method = MethodPOST
+	}
+	if a.Idempotent {
+		ctx = withIdempotent(ctx)
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		href, err := queryArguments(a.Href, as)
+		if err != nil {
+			return nil, fmt.Errorf("encode: %v", err)
+		}
+		return c.do(ctx, method, href, nil, opts)
+	}
+	body, contentType, err := encodeArguments(a.Encoding, as)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("encode: %v", err)
 	}
-	req, err := http.NewRequest(a.Method, a.Href, &buf)
+	opts = append([]func(*http.Request){Header(HeaderContentType, contentType)}, opts...)
+	return c.do(ctx, method, a.Href, body, opts)
+}
+
+// do sends a request. The URL is resolved against the base URL, the default headers
+// are set and the request options applied.
+func (c *Client) do(ctx context.Context, method string, url string, body io.Reader, opts []func(*http.Request)) (*http.Response, error) {
+	if c.baseURL != "" {
+		var err error
+		if url, err = resolveHref(c.baseURL, url); err != nil {
+			return nil, fmt.Errorf("create: %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("create: %v", err)
+	}
+	req = req.WithContext(ctx)
+	for k, vs := range c.header {
+		req.Header[k] = append([]string(nil), vs...)
 	}
-	req.Header.Set(HeaderContentType, a.Encoding)
 	for _, opt := range opts {
 		opt(req)
 	}
-	return c.httpClient.Do(req)
+	if c.cache != nil && req.Method == http.MethodGet {
+		return c.doCached(req)
+	}
+	return c.send(req)
+}
+
+// send sends the request with the underlying http.Client, retrying idempotent requests
+// if the Client has a RetryPolicy.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.retry != nil {
+		return c.sendWithRetry(req)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do: %v", err)
+	}
+	return resp, nil
+}
+
+// encodeArguments encodes the arguments as body of the given encoding and returns the
+// body together with its content type.
+func encodeArguments(encoding string, as Arguments) (io.Reader, string, error) {
+	typ, sub := splitMediaType(encoding)
+	switch typ + "/" + sub {
+	case ContentTypeURLEncoded:
+		vs := url.Values{}
+		if err := formValues(vs, "", as); err != nil {
+			return nil, "", err
+		}
+		return strings.NewReader(vs.Encode()), encoding, nil
+	case ContentTypeMultipartFormData:
+		buf := &bytes.Buffer{}
+		mw := multipart.NewWriter(buf)
+		if err := writeMultipart(mw, "", as); err != nil {
+			return nil, "", err
+		}
+		if err := mw.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf, mw.FormDataContentType(), nil
+	case ContentTypeCollectionJSON:
+		buf := &bytes.Buffer{}
+		if err := encodeCollectionJSONTemplate(buf, as); err != nil {
+			return nil, "", err
+		}
+		return buf, encoding, nil
+	default:
+		if encoding == "" {
+			encoding = ContentTypeJSON
+		}
+		buf := &bytes.Buffer{}
+		if err := json.NewEncoder(buf).Encode(as); err != nil {
+			return nil, "", err
+		}
+		return buf, encoding, nil
+	}
 }
 
+// queryArguments form encodes the arguments into the query of href, replacing
+// query parameters of the same name.
+func queryArguments(href string, as Arguments) (string, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+	vs := url.Values{}
+	if err := formValues(vs, "", as); err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for k, v := range vs {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// formValues adds the arguments to vs. Slices become multiple values, maps are
+// flattened with dotted names (e.g. "address.street").
+func formValues(vs url.Values, prefix string, as map[string]interface{}) error {
+	for k, v := range as {
+		name := prefix + k
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if err := formValues(vs, name+".", v); err != nil {
+				return err
+			}
+			continue
+		case Arguments:
+			if err := formValues(vs, name+".", v); err != nil {
+				return err
+			}
+			continue
+		}
+		ss, err := formStrings(v)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		for _, s := range ss {
+			vs.Add(name, s)
+		}
+	}
+	return nil
+}
+
+func writeMultipart(mw *multipart.Writer, prefix string, as map[string]interface{}) error {
+	for _, k := range sortedKeys(as) {
+		name := prefix + k
+		switch v := as[k].(type) {
+		case map[string]interface{}:
+			if err := writeMultipart(mw, name+".", v); err != nil {
+				return err
+			}
+		case Arguments:
+			if err := writeMultipart(mw, name+".", v); err != nil {
+				return err
+			}
+		case io.Reader:
+			if err := writeMultipartFile(mw, name, v); err != nil {
+				return err
+			}
+		case []io.Reader:
+			for _, r := range v {
+				if err := writeMultipartFile(mw, name, r); err != nil {
+					return err
+				}
+			}
+		case *multipart.FileHeader:
+			if err := writeMultipartFileHeader(mw, name, v); err != nil {
+				return err
+			}
+		case []*multipart.FileHeader:
+			for _, fh := range v {
+				if err := writeMultipartFileHeader(mw, name, fh); err != nil {
+					return err
+				}
+			}
+		default:
+			ss, err := formStrings(v)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			for _, s := range ss {
+				if err := mw.WriteField(name, s); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func writeMultipartFile(mw *multipart.Writer, name string, r io.Reader) error {
+	filename := name
+	if n, ok := r.(interface{ Name() string }); ok {
+		filename = filepath.Base(n.Name())
+	}
+	w, err := mw.CreateFormFile(name, filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func writeMultipartFileHeader(mw *multipart.Writer, name string, fh *multipart.FileHeader) error {
+	f, err := fh.Open()
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	defer f.Close()
+	w, err := mw.CreateFormFile(name, fh.Filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// formStrings converts an argument into its form values.
+func formStrings(v interface{}) ([]string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case time.Time:
+		return []string{v.Format(time.RFC3339Nano)}, nil
+	case []byte:
+		return []string{string(v)}, nil
+	}
+	if s, ok := toString(v); ok {
+		return []string{s}, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return formStrings(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		var ss []string
+		for i := 0; i < rv.Len(); i++ {
+			s, err := formStrings(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			ss = append(ss, s...)
+		}
+		return ss, nil
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return []string{s.String()}, nil
+	}
+	return nil, fmt.Errorf("unsupported form value: %T", v)
+}
+
+// SubmitItem submits args to the Action and returns the resulting Item.
+// For 201 Created responses with a Location header the created resource is fetched; the request
+// options are applied to that fetch as well, except for preconditions like IfMatch.
+// A 303 See Other is followed by the underlying http.Client.
+// Responses without content yield an empty Item. For all responses with a status code other than 2xx
+// an *HTTPError carrying the decoded error Item is returned.
+func (c *Client) SubmitItem(a Action, args Arguments, opts ...func(*http.Request)) (Item, error) {
+	return c.SubmitItemContext(context.Background(), a, args, opts...)
+}
+
+// SubmitItemContext is like SubmitItem with a context.
+func (c *Client) SubmitItemContext(ctx context.Context, a Action, args Arguments, opts ...func(*http.Request)) (Item, error) {
+	opts = append([]func(*http.Request){Accept(c.accept())}, opts...)
+	res, err := c.SubmitContext(ctx, a, args, opts...)
+	if err != nil {
+		return Item{}, err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return Item{}, fmt.Errorf("read: %v", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return Item{}, c.httpError(res.StatusCode, res.Header, data)
+	}
+	if loc := res.Header.Get(HeaderLocation); loc != "" && res.StatusCode == http.StatusCreated {
+		href, err := resolveHref(res.Request.URL.String(), loc)
+		if err != nil {
+			return Item{}, fmt.Errorf("location: %v", err)
+		}
+		return c.FetchContext(ctx, href, append(opts, withoutPreconditions)...)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return Item{}, nil
+	}
+	return c.decode(res.Header, data)
+}
+
+// withoutPreconditions removes the conditional headers of a request.
+func withoutPreconditions(req *http.Request) {
+	for _, h := range []string{HeaderIfMatch, HeaderIfNoneMatch, HeaderIfModifiedSince, HeaderIfUnmodifiedSince, "If-Range"} {
+		req.Header.Del(h)
+	}
+}
+
+// httpError creates an *HTTPError from a response, decoding the body on a best effort basis.
+func (c *Client) httpError(status int, header http.Header, data []byte) *HTTPError {
+	e := &HTTPError{
+		StatusCode: status,
+		Header:     header,
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		e.Item, _ = c.decode(header, data)
+	}
+	return e
+}
+
+// SubmitDiscard submits args to the Action and discards the response. Only errors of the
+// request itself are returned, the status code of the response is not checked.
 func (c *Client) SubmitDiscard(a Action, args Arguments, opts ...func(*http.Request)) error {
-	res, err := c.Submit(a, args, opts...)
+	return c.SubmitDiscardContext(context.Background(), a, args, opts...)
+}
+
+// SubmitDiscardContext is like SubmitDiscard with a context.
+func (c *Client) SubmitDiscardContext(ctx context.Context, a Action, args Arguments, opts ...func(*http.Request)) error {
+	res, err := c.SubmitContext(ctx, a, args, opts...)
 	if err != nil {
 		return err
 	}
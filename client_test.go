@@ -0,0 +1,276 @@
+package hyper
+
+import (
+	"context"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientFollow(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, ContentTypeHyperItem)
+		w.Write([]byte(`{"label":"` + r.URL.RequestURI() + `|` + r.Header.Get(HeaderAccept) + `|` + r.Header.Get(HeaderAcceptLanguage) + `"}`))
+	}))
+	defer s.Close()
+
+	i := Item{
+		Links: Links{
+			{Rel: RelSelf, Href: s.URL + "/orders/"},
+			{Rel: "customer", Href: "../customers/1", Accept: ContentTypeHAL, AcceptLanguage: "de"},
+			{Rel: RelSearch, Href: "search?limit=10", Template: "{?q,tag*}"},
+			{Rel: "page", Template: "/orders/{page}{?size}", Parameters: Parameters{{Name: "size", Value: 20}}},
+		},
+	}
+	tests := []struct {
+		rel  string
+		vars map[string]interface{}
+		want string
+	}{
+		{
+			rel:  "customer",
+			want: "/customers/1|application/hal+json|de",
+		},
+		{
+			rel:  RelSearch,
+			vars: map[string]interface{}{"q": "a b", "tag": []string{"x", "y"}},
+			want: "/orders/search?limit=10&q=a%20b&tag=x&tag=y|" + NewClient().accept() + "|",
+		},
+		{
+			rel:  "page",
+			vars: map[string]interface{}{"page": 2},
+			want: "/orders/2?size=20|" + NewClient().accept() + "|",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.rel, func(t *testing.T) {
+			got, err := NewClient().Follow(i, test.rel, test.vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Label != test.want {
+				t.Errorf("want: %s, got: %s", test.want, got.Label)
+			}
+		})
+	}
+
+	if _, err := NewClient().Follow(i, "unknown", nil); err == nil {
+		t.Errorf("want: error, got: nil")
+	}
+}
+
+func TestClientSubmit(t *testing.T) {
+	var got Command
+	var method string
+	var file string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		got = ExtractCommand(r)
+		if fh, ok := got.Arguments["upload"].(*multipart.FileHeader); ok {
+			f, _ := fh.Open()
+			bs, _ := ioutil.ReadAll(f)
+			file = fh.Filename + ":" + string(bs)
+			delete(got.Arguments, "upload")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		encoding string
+		args     Arguments
+		want     Arguments
+		file     string
+	}{
+		{
+			encoding: "",
+			args:     Arguments{"name": "foo", "tags": []string{"a", "b"}},
+			want:     Arguments{"name": "foo", "tags": []interface{}{"a", "b"}},
+		},
+		{
+			encoding: ContentTypeURLEncoded,
+			args:     Arguments{"name": "foo", "tags": []string{"a", "b"}, "count": 3, "address": map[string]interface{}{"city": "Bonn"}},
+			want:     Arguments{"name": "foo", "tags": []string{"a", "b"}, "count": "3", "address.city": "Bonn"},
+		},
+		{
+			encoding: ContentTypeMultipartFormData,
+			args:     Arguments{"name": "foo", "tags": []string{"a", "b"}, "upload": strings.NewReader("content")},
+			want:     Arguments{NameAction: "create", "name": "foo", "tags": []string{"a", "b"}},
+			file:     "upload:content",
+		},
+		{
+			encoding: ContentTypeCollectionJSON,
+			args:     Arguments{"name": "foo", "count": 3},
+			want:     Arguments{"name": "foo", "count": float64(3)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.encoding, func(t *testing.T) {
+			file = ""
+			a := Action{
+				Href:       s.URL,
+				Encoding:   test.encoding,
+				Parameters: Parameters{ActionParameter("create")},
+			}
+			if err := NewClient().SubmitDiscard(a, test.args); err != nil {
+				t.Fatal(err)
+			}
+			if method != MethodPOST {
+				t.Errorf("want: %s, got: %s", MethodPOST, method)
+			}
+			if got.Action != "create" {
+				t.Errorf("want: %s, got: %s", "create", got.Action)
+			}
+			if !reflect.DeepEqual(test.want, got.Arguments) {
+				t.Errorf("\nwant: %#v\ngot:  %#v", test.want, got.Arguments)
+			}
+			if file != test.file {
+				t.Errorf("want: %s, got: %s", test.file, file)
+			}
+		})
+	}
+}
+
+func TestClientSubmitItem(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		c := ExtractCommand(r)
+		if c.Arguments.String("name") == "" {
+			Write(w, http.StatusUnprocessableEntity, ErrorItem(Errors{{Name: "name", Code: ErrorCodeRequired, Message: "name is required"}}))
+			return
+		}
+		w.Header().Set(HeaderLocation, "/orders/1")
+		if c.Arguments.String("name") == "other" {
+			w.WriteHeader(http.StatusSeeOther)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/orders/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderIfMatch) != "" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		Write(w, http.StatusOK, Item{ID: "1"})
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	a := Action{Href: s.URL + "/orders", Method: MethodPOST}
+
+	tests := []struct {
+		name string
+		opts []func(*http.Request)
+	}{
+		// the precondition applies to the submit only
+		{name: "foo", opts: []func(*http.Request){IfMatch(`"v1"`)}},
+		// 303 See Other is followed by the http.Client
+		{name: "other"},
+	}
+	for _, test := range tests {
+		got, err := NewClient().SubmitItem(a, Arguments{"name": test.name}, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != "1" {
+			t.Errorf("want: %s, got: %s", "1", got.ID)
+		}
+	}
+
+	_, err := NewClient().SubmitItem(a, Arguments{})
+	herr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("want: *HTTPError, got: %#v", err)
+	}
+	if herr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("want: %d, got: %d", http.StatusUnprocessableEntity, herr.StatusCode)
+	}
+	if !herr.HasCode(ErrorCodeRequired) {
+		t.Errorf("want: code %s, got: %v", ErrorCodeRequired, herr.Errors())
+	}
+	if want := "422 Unprocessable Entity: name is required"; herr.Error() != want {
+		t.Errorf("want: %s, got: %s", want, herr.Error())
+	}
+}
+
+func TestClientFetchError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			Write(w, http.StatusNotFound, ErrorItem(Errors{{Code: "not-found", Message: "no such order"}}))
+		case "/conflict":
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+
+	_, err := NewClient().Fetch(s.URL + "/missing")
+	if !IsNotFound(err) {
+		t.Fatalf("want: not found, got: %v", err)
+	}
+	if !err.(*HTTPError).HasCode("not-found") {
+		t.Errorf("want: code %s, got: %v", "not-found", err.(*HTTPError).Errors())
+	}
+
+	if _, _, err := NewClient().FetchRaw(s.URL + "/conflict"); !IsConflict(err) {
+		t.Errorf("want: conflict, got: %v", err)
+	}
+	if _, err := NewClient().Fetch(s.URL + "/fail"); !IsServerError(err) || IsNotFound(err) {
+		t.Errorf("want: server error, got: %v", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClientOptions(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		Write(w, http.StatusOK, Item{Label: r.URL.Path + "|" + r.Header.Get(HeaderAuthorization) + "|" + r.Header.Get("X-Trace")})
+	}))
+	defer s.Close()
+
+	var traced bool
+	c := NewClient(
+		WithBaseURL(s.URL+"/api/"),
+		WithHeader(HeaderAuthorization, "Bearer token"),
+		WithTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			traced = true
+			return http.DefaultTransport.RoundTrip(r)
+		})),
+	)
+	got, err := c.Fetch("orders", Header("X-Trace", "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/api/orders|Bearer token|1"; got.Label != want {
+		t.Errorf("want: %s, got: %s", want, got.Label)
+	}
+	if !traced {
+		t.Errorf("want: request through transport")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.FetchContext(ctx, "orders"); err == nil {
+		t.Errorf("want: error for canceled context, got: nil")
+	}
+
+	c = NewClient(WithTimeout(50 * time.Millisecond))
+	if _, err := c.Fetch(s.URL + "/slow"); err == nil {
+		t.Errorf("want: timeout error, got: nil")
+	}
+}
@@ -0,0 +1,318 @@
+package hyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ContentTypeCollectionJSON is the media type of Collection+JSON.
+// See: http://amundsen.com/media-types/collection/format/
+const ContentTypeCollectionJSON = "application/vnd.collection+json"
+
+// RelCreate is used for the action that adds an item to a collection.
+const RelCreate = "create"
+
+// CollectionJSONDocument is the top-level Collection+JSON document.
+type CollectionJSONDocument struct {
+	Collection CollectionJSON `json:"collection"`
+}
+
+// CollectionJSON is a Collection+JSON collection.
+type CollectionJSON struct {
+	Version  string                  `json:"version"`
+	Href     string                  `json:"href,omitempty"`
+	Links    []CollectionJSONLink    `json:"links,omitempty"`
+	Items    []CollectionJSONItem    `json:"items,omitempty"`
+	Queries  []CollectionJSONQuery   `json:"queries,omitempty"`
+	Template *CollectionJSONTemplate `json:"template,omitempty"`
+	Error    *CollectionJSONError    `json:"error,omitempty"`
+}
+
+// CollectionJSONLink is a Collection+JSON link.
+type CollectionJSONLink struct {
+	Href   string `json:"href"`
+	Rel    string `json:"rel"`
+	Name   string `json:"name,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+	Render string `json:"render,omitempty"`
+}
+
+// CollectionJSONItem is an item of a Collection+JSON collection.
+type CollectionJSONItem struct {
+	Href  string               `json:"href,omitempty"`
+	Data  []CollectionJSONData `json:"data,omitempty"`
+	Links []CollectionJSONLink `json:"links,omitempty"`
+}
+
+// CollectionJSONQuery is a Collection+JSON query.
+type CollectionJSONQuery struct {
+	Href   string               `json:"href"`
+	Rel    string               `json:"rel"`
+	Name   string               `json:"name,omitempty"`
+	Prompt string               `json:"prompt,omitempty"`
+	Data   []CollectionJSONData `json:"data,omitempty"`
+}
+
+// CollectionJSONTemplate is a Collection+JSON write template.
+type CollectionJSONTemplate struct {
+	Data []CollectionJSONData `json:"data"`
+}
+
+// CollectionJSONData is a name value pair of Collection+JSON.
+type CollectionJSONData struct {
+	Name   string      `json:"name"`
+	Value  interface{} `json:"value,omitempty"`
+	Prompt string      `json:"prompt,omitempty"`
+}
+
+// CollectionJSONError is a Collection+JSON error.
+type CollectionJSONError struct {
+	Title   string `json:"title,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ItemToCollectionJSON converts an Item representing a collection into Collection+JSON.
+//
+// The self link becomes the href of the collection and sub-Items become its items,
+// their Properties becoming data and their self links their href.
+// Links with a query Template (e.g. those built by MakeFilterLink or from Meta templates)
+// become queries with one data element per template variable; fixed query parameters are
+// kept as data with their value. Other templated links are dropped, all remaining links
+// become links.
+// The first Action with rel "create" (or else the first Action with method POST) becomes the
+// template. Errors are folded into the single error object of Collection+JSON.
+// Properties of the collection itself have no Collection+JSON counterpart and are dropped.
+func ItemToCollectionJSON(i Item) CollectionJSONDocument {
+	c := CollectionJSON{Version: "1.0"}
+	for _, l := range i.Links {
+		switch {
+		case l.Rel == RelSelf && c.Href == "":
+			c.Href = l.Href
+		case l.Template != "":
+			if q, ok := linkToCollectionJSONQuery(l); ok {
+				c.Queries = append(c.Queries, q)
+			}
+		default:
+			c.Links = append(c.Links, linkToCollectionJSON(l))
+		}
+	}
+	for _, sub := range i.Items {
+		ci := CollectionJSONItem{}
+		for _, p := range sub.Properties {
+			ci.Data = append(ci.Data, CollectionJSONData{Name: p.Name, Value: p.Value, Prompt: p.Label})
+		}
+		for _, l := range sub.Links {
+			if l.Rel == RelSelf && ci.Href == "" {
+				ci.Href = l.Href
+				continue
+			}
+			ci.Links = append(ci.Links, linkToCollectionJSON(l))
+		}
+		c.Items = append(c.Items, ci)
+	}
+	if a, ok := collectionAction(i.Actions); ok {
+		t := &CollectionJSONTemplate{Data: []CollectionJSONData{}}
+		for _, p := range a.Parameters {
+			t.Data = append(t.Data, CollectionJSONData{Name: p.Name, Value: p.Value, Prompt: p.Label})
+		}
+		c.Template = t
+	}
+	if len(i.Errors) > 0 {
+		var msgs []string
+		for _, e := range i.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		c.Error = &CollectionJSONError{
+			Title:   i.Errors[0].Label,
+			Code:    i.Errors[0].Code,
+			Message: strings.Join(msgs, "\n"),
+		}
+	}
+	return CollectionJSONDocument{Collection: c}
+}
+
+func collectionAction(as Actions) (Action, bool) {
+	if a, ok := as.FindByRel(RelCreate); ok {
+		return a, true
+	}
+	return as.Find(func(a Action) bool {
+		return a.Method == MethodPOST
+	})
+}
+
+func linkToCollectionJSON(l Link) CollectionJSONLink {
+	cl := CollectionJSONLink{
+		Href:   l.Href,
+		Rel:    l.Rel,
+		Prompt: l.Label,
+		Render: "link",
+	}
+	if strings.HasPrefix(l.Type, "image/") {
+		cl.Render = "image"
+	}
+	return cl
+}
+
+func linkToCollectionJSONQuery(l Link) (CollectionJSONQuery, bool) {
+	target, names, ok := queryTemplate(l)
+	if !ok {
+		return CollectionJSONQuery{}, false
+	}
+	q := CollectionJSONQuery{
+		Rel:    l.Rel,
+		Prompt: l.Label,
+	}
+	query := target.Query()
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range query[k] {
+			q.Data = append(q.Data, CollectionJSONData{Name: k, Value: v})
+		}
+	}
+	for _, n := range names {
+		d := CollectionJSONData{Name: n, Value: ""}
+		if p, ok := l.Parameters.FindByName(n); ok {
+			d.Prompt = p.Label
+			if s, ok := toString(p.Value); ok {
+				d.Value = s
+			}
+		}
+		q.Data = append(q.Data, d)
+	}
+	target.RawQuery = ""
+	q.Href = target.String()
+	return q, true
+}
+
+// CollectionJSONToItem converts a Collection+JSON document into an Item.
+// Queries become links with a query Template over all their data elements, the template
+// becomes a POST Action with rel "create" (encoded as Collection+JSON write template, see Client.Submit)
+// and the error becomes a single Error.
+func CollectionJSONToItem(d CollectionJSONDocument) Item {
+	c := d.Collection
+	i := Item{}
+	if c.Href != "" {
+		i.AddLink(Link{Rel: RelSelf, Href: c.Href})
+	}
+	for _, cl := range c.Links {
+		i.AddLink(collectionJSONToLink(cl))
+	}
+	for _, q := range c.Queries {
+		l := Link{
+			Rel:   q.Rel,
+			Href:  q.Href,
+			Label: q.Prompt,
+		}
+		var names []string
+		for _, d := range q.Data {
+			names = append(names, d.Name)
+			l.Parameters = append(l.Parameters, Parameter{Name: d.Name, Type: TypeText, Label: d.Prompt, Value: d.Value})
+		}
+		if len(names) > 0 {
+			l.Template = "{?" + strings.Join(names, ",") + "}"
+		}
+		i.AddLink(l)
+	}
+	for _, ci := range c.Items {
+		sub := Item{}
+		for _, d := range ci.Data {
+			sub.AddProperty(Property{Name: d.Name, Value: d.Value, Label: d.Prompt})
+		}
+		if ci.Href != "" {
+			sub.AddLink(Link{Rel: RelSelf, Href: ci.Href})
+		}
+		for _, cl := range ci.Links {
+			sub.AddLink(collectionJSONToLink(cl))
+		}
+		i.AddItem(sub)
+	}
+	if c.Template != nil {
+		a := Action{
+			Rel:      RelCreate,
+			Href:     c.Href,
+			Method:   MethodPOST,
+			Encoding: ContentTypeCollectionJSON,
+		}
+		for _, d := range c.Template.Data {
+			a.Parameters = append(a.Parameters, Parameter{Name: d.Name, Type: TypeText, Label: d.Prompt, Value: d.Value})
+		}
+		i.AddAction(a)
+	}
+	if c.Error != nil {
+		i.Errors = append(i.Errors, Error{
+			Label:   c.Error.Title,
+			Code:    c.Error.Code,
+			Message: c.Error.Message,
+		})
+	}
+	return i
+}
+
+func collectionJSONToLink(cl CollectionJSONLink) Link {
+	l := Link{
+		Rel:   cl.Rel,
+		Href:  cl.Href,
+		Label: cl.Prompt,
+	}
+	if cl.Render == "image" {
+		l.Render = []string{RenderTransclude}
+	}
+	return l
+}
+
+// EncodeCollectionJSON encodes an Item as Collection+JSON.
+func EncodeCollectionJSON(w io.Writer, i Item) error {
+	return json.NewEncoder(w).Encode(ItemToCollectionJSON(i))
+}
+
+// DecodeCollectionJSON decodes a Collection+JSON document into an Item.
+func DecodeCollectionJSON(r io.Reader) (Item, error) {
+	d := CollectionJSONDocument{}
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return Item{}, err
+	}
+	return CollectionJSONToItem(d), nil
+}
+
+// encodeCollectionJSONTemplate encodes the arguments as Collection+JSON write template:
+//
+//	{"template":{"data":[{"name":"a","value":"x"}, ...]}}
+//
+// The data elements are ordered by name.
+func encodeCollectionJSONTemplate(w io.Writer, as Arguments) error {
+	var names []string
+	for n := range as {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	t := CollectionJSONTemplate{Data: []CollectionJSONData{}}
+	for _, n := range names {
+		t.Data = append(t.Data, CollectionJSONData{Name: n, Value: as[n]})
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Template CollectionJSONTemplate `json:"template"`
+	}{Template: t})
+}
+
+// decodeCollectionJSONTemplate decodes a Collection+JSON write template into arguments.
+func decodeCollectionJSONTemplate(r io.Reader) (Arguments, error) {
+	var body struct {
+		Template CollectionJSONTemplate `json:"template"`
+	}
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode template: %v", err)
+	}
+	as := Arguments{}
+	for _, d := range body.Template.Data {
+		as[d.Name] = d.Value
+	}
+	return as, nil
+}
@@ -0,0 +1,64 @@
+package hyper
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestItemToCollectionJSON(t *testing.T) {
+	m := Meta{Sort: Sort{{Name: "name", Order: SortOrderAscending}}, Limit: 10}
+	i := Item{
+		Links: Links{
+			{Rel: RelSelf, Href: "http://localhost/people"},
+			{Rel: RelNext, Href: "http://localhost/people" + m.Next().Query()},
+			MakeFilterLink(FilterConfiguration{}, m.FilterTemplate(), nil, "filter"),
+		},
+		Items: Items{
+			{
+				Properties: Properties{{Name: "name", Label: "Name", Value: "Jane"}},
+				Links:      Links{{Rel: RelSelf, Href: "http://localhost/people/1"}},
+			},
+		},
+		Actions: Actions{
+			{Rel: RelCreate, Href: "http://localhost/people", Method: MethodPOST, Parameters: Parameters{{Name: "name", Label: "Name", Type: TypeText}}},
+		},
+		Errors: Errors{{Code: "x", Message: "a"}, {Message: "b"}},
+	}
+	i.Links[2].Href = "http://localhost/people"
+
+	bs, err := json.Marshal(ItemToCollectionJSON(i))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	json.Unmarshal(bs, &got)
+	want := map[string]interface{}{}
+	json.Unmarshal([]byte(`{"collection": {
+		"version": "1.0",
+		"href": "http://localhost/people",
+		"links": [{"href": "http://localhost/people?sort=name%2CASC&skip=10&limit=10", "rel": "next", "render": "link"}],
+		"items": [{"href": "http://localhost/people/1", "data": [{"name": "name", "value": "Jane", "prompt": "Name"}]}],
+		"queries": [{"href": "http://localhost/people", "rel": "filter", "data": [
+			{"name": "limit", "value": "10"},
+			{"name": "sort", "value": "name,ASC"},
+			{"name": "filter", "value": ""}
+		]}],
+		"template": {"data": [{"name": "name", "prompt": "Name"}]},
+		"error": {"code": "x", "message": "a\nb"}
+	}}`), &want)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant: %v\ngot:  %v", want, got)
+	}
+
+	back := CollectionJSONToItem(ItemToCollectionJSON(i))
+	if l, ok := back.Links.FindByRel("filter"); !ok || l.Template != "{?limit,sort,filter}" {
+		t.Errorf("unexpected filter link: %#v", l)
+	}
+	if a, ok := back.Actions.FindByRel(RelCreate); !ok || a.Href != "http://localhost/people" || a.Encoding != ContentTypeCollectionJSON || len(a.Parameters) != 1 {
+		t.Errorf("unexpected create action: %#v", a)
+	}
+	if len(back.Items) != 1 || len(back.Errors) != 1 {
+		t.Errorf("unexpected item: %#v", back)
+	}
+}
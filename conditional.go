@@ -0,0 +1,131 @@
+package hyper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrorCodePreconditionFailed is used for Errors of requests whose preconditions do not hold.
+const ErrorCodePreconditionFailed = "precondition-failed"
+
+// Validators are the validators of a representation used for conditional requests.
+type Validators struct {
+	// ETag is the entity tag including its quotes (e.g. `"v1"` or `W/"v1"`).
+	// If empty WriteConditional computes a strong ETag from the encoded representation.
+	ETag         string
+	LastModified time.Time
+}
+
+// WriteConditional writes the Item like Respond, but sets the ETag and Last-Modified headers
+// and answers with 304 Not Modified if the If-None-Match or If-Modified-Since header of
+// a GET or HEAD request shows that the client already has the current representation.
+func (n *Negotiator) WriteConditional(w http.ResponseWriter, r *http.Request, status int, i Item, v Validators) {
+	ct, buf, ok := n.encode(w, r, i)
+	if !ok {
+		return
+	}
+	if v.ETag == "" {
+		v.ETag = StrongETag(buf.Bytes())
+	}
+	w.Header().Set(HeaderETag, v.ETag)
+	if !v.LastModified.IsZero() {
+		w.Header().Set(HeaderLastModified, v.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if status == http.StatusOK && notModified(r, v) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set(HeaderContentType, ct)
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		buf.WriteTo(w)
+	}
+}
+
+// WriteConditional writes the Item using the DefaultNegotiator.
+func WriteConditional(w http.ResponseWriter, r *http.Request, status int, i Item, v Validators) {
+	DefaultNegotiator.WriteConditional(w, r, status, i, v)
+}
+
+// StrongETag computes a strong entity tag from the content of a representation.
+func StrongETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func notModified(r *http.Request, v Validators) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get(HeaderIfNoneMatch); inm != "" {
+		return etagMatches(inm, v.ETag, false)
+	}
+	if ims := r.Header.Get(HeaderIfModifiedSince); ims != "" && !v.LastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !v.LastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// CheckIfMatch checks the If-Match and If-Unmodified-Since preconditions of a request against
+// the current validators of the resource, e.g. before applying a PATCH or DELETE Action.
+// If a precondition fails it writes a 412 Precondition Failed error Item and returns false.
+func CheckIfMatch(w http.ResponseWriter, r *http.Request, v Validators) bool {
+	if im := r.Header.Get(HeaderIfMatch); im != "" {
+		if !etagMatches(im, v.ETag, true) {
+			writePreconditionFailed(w, r, fmt.Sprintf("resource has changed: %s does not match %s", v.ETag, im))
+			return false
+		}
+		return true
+	}
+	if ius := r.Header.Get(HeaderIfUnmodifiedSince); ius != "" && !v.LastModified.IsZero() {
+		t, err := http.ParseTime(ius)
+		if err == nil && v.LastModified.Truncate(time.Second).After(t) {
+			writePreconditionFailed(w, r, fmt.Sprintf("resource has been modified since %s", ius))
+			return false
+		}
+	}
+	return true
+}
+
+func writePreconditionFailed(w http.ResponseWriter, r *http.Request, msg string) {
+	WriteFor(w, r, http.StatusPreconditionFailed, Item{
+		Errors: Errors{{Code: ErrorCodePreconditionFailed, Message: msg}},
+	})
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header matches etag.
+// The strong comparison (for If-Match) never matches weak entity tags.
+// "*" matches any current representation, even one without an entity tag.
+func etagMatches(header string, etag string, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if etag == "" {
+		return false
+	}
+	if strong && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if strong && strings.HasPrefix(t, "W/") {
+			continue
+		}
+		if strings.TrimPrefix(t, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatch makes a request conditional on the entity tag of the target resource.
+func IfMatch(etag string) func(*http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set(HeaderIfMatch, etag)
+	}
+}
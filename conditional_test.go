@@ -0,0 +1,82 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteConditional(t *testing.T) {
+	modified := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	i := Item{Label: "Order"}
+
+	w := httptest.NewRecorder()
+	WriteConditional(w, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, i, Validators{LastModified: modified})
+	etag := w.Header().Get(HeaderETag)
+	if etag == "" || w.Code != http.StatusOK || w.Body.Len() == 0 {
+		t.Fatalf("unexpected response: %d %v", w.Code, w.Header())
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{name: "matching etag", header: HeaderIfNoneMatch, value: `"other", ` + etag, want: http.StatusNotModified},
+		{name: "weak etag", header: HeaderIfNoneMatch, value: "W/" + etag, want: http.StatusNotModified},
+		{name: "other etag", header: HeaderIfNoneMatch, value: `"other"`, want: http.StatusOK},
+		{name: "not modified since", header: HeaderIfModifiedSince, value: modified.Format(http.TimeFormat), want: http.StatusNotModified},
+		{name: "modified since", header: HeaderIfModifiedSince, value: modified.Add(-time.Hour).Format(http.TimeFormat), want: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(test.header, test.value)
+			w := httptest.NewRecorder()
+			WriteConditional(w, r, http.StatusOK, i, Validators{LastModified: modified})
+			if w.Code != test.want {
+				t.Errorf("want: %d, got: %d", test.want, w.Code)
+			}
+			if got := w.Header().Get(HeaderETag); got != etag {
+				t.Errorf("want: %s, got: %s", etag, got)
+			}
+			if w.Code == http.StatusNotModified && w.Body.Len() > 0 {
+				t.Errorf("want: empty body, got: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	v := Validators{ETag: `"v2"`}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !CheckIfMatch(w, r, v) {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	a := Action{Href: s.URL, Method: MethodDELETE}
+	err := NewClient().SubmitDiscard(a, nil, IfMatch(`"v2"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewClient().SubmitItem(a, nil, IfMatch(`"v1"`))
+	if !IsPreconditionFailed(err) {
+		t.Fatalf("want: precondition failed, got: %v", err)
+	}
+	if !err.(*HTTPError).HasCode(ErrorCodePreconditionFailed) {
+		t.Errorf("want: code %s, got: %v", ErrorCodePreconditionFailed, err.(*HTTPError).Errors())
+	}
+
+	// "*" matches a resource without an entity tag
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodDelete, "/", nil)
+	r.Header.Set(HeaderIfMatch, "*")
+	if !CheckIfMatch(w, r, Validators{LastModified: time.Now()}) {
+		t.Errorf("want: match, got: %d", w.Code)
+	}
+}
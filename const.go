@@ -52,6 +52,8 @@ const (
 	TypeDatalist = "datalist"
 	TypeInteger  = "integer"
 	TypeBool     = "bool"
+	// TypeObject is used for parameters composed of the parameters in Components
+	TypeObject = "object"
 )
 
 const (
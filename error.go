@@ -1,9 +1,16 @@
 package hyper
 
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
 // Error .
 type Error struct {
 	Label       string `json:"label,omitempty"`
 	Description string `json:"description,omitempty"`
+	Name        string `json:"name,omitempty"`
 	Message     string `json:"message"`
 	Code        string `json:"code,omitempty"`
 }
@@ -11,6 +18,15 @@ type Error struct {
 // Errors .
 type Errors []Error
 
+// Error implements the error interface by joining all messages.
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
 // Find
 func (es Errors) Find(accept func(e Error) bool) (Error, bool) {
 	for _, e := range es {
@@ -32,9 +48,30 @@ func (es Errors) Filter(accept func(e Error) bool) Errors {
 	return res
 }
 
+// FilterByName returns all Errors that refer to the named parameter
+func (es Errors) FilterByName(name string) Errors {
+	return es.Filter(ErrorNameEquals(name))
+}
+
+func ErrorNameEquals(name string) func(Error) bool {
+	return func(e Error) bool {
+		return name == e.Name
+	}
+}
+
+func ErrorCodeEquals(code string) func(Error) bool {
+	return func(e Error) bool {
+		return code == e.Code
+	}
+}
+
 func ErrorItem(errs ...error) Item {
 	res := Item{}
 	for _, err := range errs {
+		if es, ok := err.(Errors); ok {
+			res.Errors = append(res.Errors, es...)
+			continue
+		}
 		e := Error{Message: err.Error()}
 		if errC, ok := err.(errorCoder); ok {
 			e.Code = errC.Code()
@@ -47,3 +84,91 @@ func ErrorItem(errs ...error) Item {
 type errorCoder interface {
 	Code() string
 }
+
+// Error codes used when validating arguments.
+const (
+	ErrorCodeRequired  = "required"
+	ErrorCodeType      = "type"
+	ErrorCodePattern   = "pattern"
+	ErrorCodeMin       = "min"
+	ErrorCodeMax       = "max"
+	ErrorCodeMinLength = "min-length"
+	ErrorCodeMaxLength = "max-length"
+	ErrorCodeStep      = "step"
+	ErrorCodeOption    = "option"
+	ErrorCodeMultiple  = "multiple"
+)
+
+// HTTPError is returned by the Client for responses with a status code other than 2xx.
+// Item is the decoded response body, which usually carries the Errors written by the server.
+type HTTPError struct {
+	StatusCode int
+	Header     http.Header
+	Item       Item
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Item.Errors) > 0 {
+		msg += ": " + e.Item.Errors.Error()
+	}
+	return msg
+}
+
+// Errors returns the Errors of the response Item.
+func (e *HTTPError) Errors() Errors {
+	return e.Item.Errors
+}
+
+// HasCode reports whether the response Item carries an Error with the given code.
+func (e *HTTPError) HasCode(code string) bool {
+	_, ok := e.Item.Errors.Find(ErrorCodeEquals(code))
+	return ok
+}
+
+// IsStatus reports whether err is an *HTTPError with the given status code.
+func IsStatus(err error, status int) bool {
+	e, ok := err.(*HTTPError)
+	return ok && e.StatusCode == status
+}
+
+// IsBadRequest reports whether err is an *HTTPError with status 400 Bad Request.
+func IsBadRequest(err error) bool {
+	return IsStatus(err, http.StatusBadRequest)
+}
+
+// IsUnauthorized reports whether err is an *HTTPError with status 401 Unauthorized.
+func IsUnauthorized(err error) bool {
+	return IsStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an *HTTPError with status 403 Forbidden.
+func IsForbidden(err error) bool {
+	return IsStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound reports whether err is an *HTTPError with status 404 Not Found.
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an *HTTPError with status 409 Conflict.
+func IsConflict(err error) bool {
+	return IsStatus(err, http.StatusConflict)
+}
+
+// IsPreconditionFailed reports whether err is an *HTTPError with status 412 Precondition Failed.
+func IsPreconditionFailed(err error) bool {
+	return IsStatus(err, http.StatusPreconditionFailed)
+}
+
+// IsUnprocessableEntity reports whether err is an *HTTPError with status 422 Unprocessable Entity.
+func IsUnprocessableEntity(err error) bool {
+	return IsStatus(err, http.StatusUnprocessableEntity)
+}
+
+// IsServerError reports whether err is an *HTTPError with a 5xx status.
+func IsServerError(err error) bool {
+	e, ok := err.(*HTTPError)
+	return ok && e.StatusCode >= 500 && e.StatusCode <= 599
+}
@@ -0,0 +1,570 @@
+package hyper
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Evaluator applies a Meta to in-memory slices.
+// The zero value accesses the fields of structs (by hyper tag, json tag or name; nested fields by
+// dotted names like "address.city"), the keys of maps and the Properties of Items (as well as
+// their id, type and label).
+type Evaluator struct {
+	// Field returns the value of the named field of an element. Optional.
+	Field func(v interface{}, name string) (interface{}, bool)
+	// Key returns the key of an element used for After and Before. Optional, by default the
+	// field "id" is used.
+	Key func(v interface{}) string
+	// Search reports whether an element matches the search term of the Meta. Optional, by default
+	// an element matches if any of its string fields contains the term ignoring case.
+	Search func(v interface{}, term string) bool
+}
+
+// ApplyMeta applies the Meta to the slice pointed at by slicePtr using the default Evaluator.
+// See Evaluator.Apply.
+func ApplyMeta(m Meta, slicePtr interface{}) (int, error) {
+	return Evaluator{}.Apply(m, slicePtr)
+}
+
+// Apply filters, searches, sorts and pages the slice pointed at by slicePtr in place and returns the
+// number of elements matching the filter and search (before paging), e.g. for PaginationLinks.
+//
+// Comparisons are type-aware: numbers are compared numerically, times chronologically, bools
+// with false before true and everything else as strings; filter values are converted to the type
+// of the field. Like matches patterns with % wildcards or, without wildcards, a substring, both
+// ignoring case. Missing fields, empty groups and paging follow the rules described for Meta.
+//
+// After keeps the elements following the one with the given key, Before the Limit elements
+// preceding it; an unknown key yields no elements.
+func (e Evaluator) Apply(m Meta, slicePtr interface{}) (int, error) {
+	rv := reflect.ValueOf(slicePtr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return 0, fmt.Errorf("apply: expected a pointer to a slice, got %T", slicePtr)
+	}
+	sv := rv.Elem()
+	var elems []reflect.Value
+	for i := 0; i < sv.Len(); i++ {
+		el := sv.Index(i)
+		ok, err := e.Match(m.Filter, el.Interface())
+		if err != nil {
+			return 0, err
+		}
+		if ok && m.Search != "" {
+			ok = e.search(el.Interface(), m.Search)
+		}
+		if ok {
+			elems = append(elems, el)
+		}
+	}
+	total := len(elems)
+
+	if !m.Sort.IsZero() {
+		sort.SliceStable(elems, func(i, j int) bool {
+			return e.less(m.Sort, elems[i].Interface(), elems[j].Interface())
+		})
+	}
+
+	switch {
+	case m.Before != "":
+		elems = elems[:e.index(elems, m.Before, 0)]
+		if m.Limit > 0 && uint64(len(elems)) > m.Limit {
+			elems = elems[uint64(len(elems))-m.Limit:]
+		}
+	case m.After != "":
+		elems = elems[e.index(elems, m.After, len(elems)-1)+1:]
+		if m.Limit > 0 && uint64(len(elems)) > m.Limit {
+			elems = elems[:m.Limit]
+		}
+	default:
+		if m.Skip >= uint64(len(elems)) {
+			elems = nil
+		} else {
+			elems = elems[m.Skip:]
+		}
+		if m.Limit > 0 && uint64(len(elems)) > m.Limit {
+			elems = elems[:m.Limit]
+		}
+	}
+
+	res := reflect.MakeSlice(sv.Type(), len(elems), len(elems))
+	for i, el := range elems {
+		res.Index(i).Set(el)
+	}
+	sv.Set(res)
+	return total, nil
+}
+
+// index returns the index of the element with the key or def if there is none.
+func (e Evaluator) index(elems []reflect.Value, key string, def int) int {
+	for i, el := range elems {
+		if e.key(el.Interface()) == key {
+			return i
+		}
+	}
+	return def
+}
+
+// Match reports whether v matches all components of the Filter. The components of groups
+// are matched recursively.
+func (e Evaluator) Match(f Filter, v interface{}) (bool, error) {
+	for _, fc := range f {
+		ok, err := e.matchComponent(fc, v)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (e Evaluator) matchComponent(fc FilterComponent, v interface{}) (bool, error) {
+	if fc.IsEmptyGroup() {
+		// empty groups are ignored
+		return true, nil
+	}
+	switch fc.Operator {
+	case FilterOperatorAnd:
+		return e.Match(fc.Components(), v)
+	case FilterOperatorOr:
+		for _, sub := range fc.Components() {
+			if sub.IsEmptyGroup() {
+				continue
+			}
+			ok, err := e.matchComponent(sub, v)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case FilterOperatorNot:
+		ok, err := e.Match(fc.Components(), v)
+		return !ok && err == nil, err
+	}
+	fv, ok := e.field(v, fc.Name)
+	if !ok || fv == nil {
+		// neither the operator nor its negation matches a missing field
+		return false, nil
+	}
+	ok, err := matchOperator(fc, fv)
+	if err != nil {
+		return false, fmt.Errorf("filter %s: %v", fc.Name, err)
+	}
+	switch fc.Operator {
+	case FilterOperatorNotEquals, FilterOperatorNotIn, FilterOperatorNotLike, FilterOperatorNotBetween:
+		return !ok, nil
+	}
+	return ok, nil
+}
+
+// matchOperator evaluates the positive form of the operator of fc for the field value fv.
+func matchOperator(fc FilterComponent, fv interface{}) (bool, error) {
+	switch fc.Operator {
+	case FilterOperatorEquals, FilterOperatorNotEquals, "":
+		c, err := compareValues(fv, fc.Value)
+		return c == 0, err
+	case FilterOperatorLessThen:
+		c, err := compareValues(fv, fc.Value)
+		return c < 0, err
+	case FilterOperatorLessThenOrEquals:
+		c, err := compareValues(fv, fc.Value)
+		return c <= 0, err
+	case FilterOperatorGreaterThen:
+		c, err := compareValues(fv, fc.Value)
+		return c > 0, err
+	case FilterOperatorGreaterThenOrEquals:
+		c, err := compareValues(fv, fc.Value)
+		return c >= 0, err
+	case FilterOperatorIn, FilterOperatorNotIn:
+		for _, x := range filterValues(fc.Value) {
+			c, err := compareValues(fv, x)
+			if err != nil {
+				return false, err
+			}
+			if c == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case FilterOperatorBetween, FilterOperatorNotBetween:
+		bounds := filterValues(fc.Value)
+		if len(bounds) != 2 {
+			return false, fmt.Errorf("invalid size of filter-between values (must == 2): %d", len(bounds))
+		}
+		lo, err := compareValues(fv, bounds[0])
+		if err != nil {
+			return false, err
+		}
+		hi, err := compareValues(fv, bounds[1])
+		if err != nil {
+			return false, err
+		}
+		return lo >= 0 && hi <= 0, nil
+	case FilterOperatorLike, FilterOperatorNotLike:
+		pattern, ok := toString(fc.Value)
+		if !ok {
+			return false, fmt.Errorf("invalid like pattern: %v", fc.Value)
+		}
+		return like(fmt.Sprint(fv), pattern), nil
+	}
+	return false, fmt.Errorf("unsupported operator: %s", fc.Operator)
+}
+
+// like matches s against a pattern with % wildcards or, if the pattern has no wildcards,
+// checks whether s contains the pattern. Both ignore case.
+func like(s string, pattern string) bool {
+	if !strings.Contains(pattern, "%") {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(pattern))
+	}
+	parts := strings.Split(pattern, "%")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	re, err := regexp.Compile("(?is)^" + strings.Join(parts, ".*") + "$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+// filterValues returns the values of a filter component with several values.
+func filterValues(v interface{}) []interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return []interface{}{v}
+	}
+	vs := make([]interface{}, rv.Len())
+	for i := range vs {
+		vs[i] = rv.Index(i).Interface()
+	}
+	return vs
+}
+
+func (e Evaluator) less(s Sort, a interface{}, b interface{}) bool {
+	for _, sc := range s {
+		av, _ := e.field(a, sc.Name)
+		bv, _ := e.field(b, sc.Name)
+		var c int
+		switch {
+		case av == nil && bv == nil:
+			c = 0
+		case av == nil:
+			// missing values come first in both orders
+			c = -1
+		case bv == nil:
+			c = 1
+		default:
+			c, _ = compareValues(av, bv)
+			if sc.Order == SortOrderDescending {
+				c = -c
+			}
+		}
+		if c != 0 {
+			return c < 0
+		}
+	}
+	return false
+}
+
+func (e Evaluator) field(v interface{}, name string) (interface{}, bool) {
+	if e.Field != nil {
+		return e.Field(v, name)
+	}
+	return FieldValue(v, name)
+}
+
+func (e Evaluator) key(v interface{}) string {
+	if e.Key != nil {
+		return e.Key(v)
+	}
+	if fv, ok := e.field(v, "id"); ok && fv != nil {
+		return fmt.Sprint(fv)
+	}
+	return ""
+}
+
+func (e Evaluator) search(v interface{}, term string) bool {
+	if e.Search != nil {
+		return e.Search(v, term)
+	}
+	term = strings.ToLower(term)
+	for _, s := range stringValues(reflect.ValueOf(v), 0) {
+		if strings.Contains(strings.ToLower(s), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldValue returns the value of the named field of v. v may be a struct (fields are found by hyper
+// tag, json tag or case-insensitive name), a map with string keys or an Item (Properties by name,
+// as well as id, type and label). Nested fields are addressed with dotted names.
+func FieldValue(v interface{}, name string) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	for _, part := range strings.Split(name, ".") {
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				return nil, false
+			}
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() {
+			return nil, false
+		}
+		if i, ok := rv.Interface().(Item); ok {
+			fv, ok := itemFieldValue(i, part)
+			if !ok {
+				return nil, false
+			}
+			rv = reflect.ValueOf(fv)
+			continue
+		}
+		switch rv.Kind() {
+		case reflect.Struct:
+			f, ok := structField(rv, part)
+			if !ok {
+				return nil, false
+			}
+			rv = f
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			f := rv.MapIndex(reflect.ValueOf(part).Convert(rv.Type().Key()))
+			if !f.IsValid() {
+				return nil, false
+			}
+			rv = f
+		default:
+			return nil, false
+		}
+	}
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, true
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, true
+	}
+	return rv.Interface(), true
+}
+
+func itemFieldValue(i Item, name string) (interface{}, bool) {
+	if p, ok := i.Properties.FindByName(name); ok {
+		return p.Value, true
+	}
+	switch name {
+	case "id":
+		return i.ID, true
+	case "type":
+		return i.Type, true
+	case "label":
+		return i.Label, true
+	}
+	return nil, false
+}
+
+func structField(rv reflect.Value, name string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		n, ok := fieldName(sf)
+		if !ok {
+			continue
+		}
+		jn := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if n == name || jn == name || strings.EqualFold(sf.Name, name) {
+			return rv.Field(i), true
+		}
+	}
+	// embedded structs
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.Anonymous && indirectType(sf.Type).Kind() == reflect.Struct {
+			f := rv.Field(i)
+			if f.Kind() == reflect.Ptr {
+				if f.IsNil() {
+					continue
+				}
+				f = f.Elem()
+			}
+			if v, ok := structField(f, name); ok {
+				return v, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// stringValues collects the string fields of structs, maps and Items.
+func stringValues(rv reflect.Value, depth int) []string {
+	if depth > 4 {
+		return nil
+	}
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	if i, ok := rv.Interface().(Item); ok {
+		ss := []string{i.Label}
+		for _, p := range i.Properties {
+			ss = append(ss, stringValues(reflect.ValueOf(p.Value), depth+1)...)
+		}
+		return ss
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return []string{rv.String()}
+	case reflect.Struct:
+		if rv.Type() == typeTime {
+			return nil
+		}
+		var ss []string
+		for i := 0; i < rv.NumField(); i++ {
+			if rv.Type().Field(i).PkgPath == "" {
+				ss = append(ss, stringValues(rv.Field(i), depth+1)...)
+			}
+		}
+		return ss
+	case reflect.Map, reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		var ss []string
+		if rv.Kind() == reflect.Map {
+			for _, k := range rv.MapKeys() {
+				ss = append(ss, stringValues(rv.MapIndex(k), depth+1)...)
+			}
+			return ss
+		}
+		for i := 0; i < rv.Len(); i++ {
+			ss = append(ss, stringValues(rv.Index(i), depth+1)...)
+		}
+		return ss
+	}
+	return nil
+}
+
+// compareValues compares a field value with another value converted to the type of the field.
+func compareValues(a interface{}, b interface{}) (int, error) {
+	switch a := normalizeValue(a).(type) {
+	case time.Time:
+		var bt time.Time
+		switch b := normalizeValue(b).(type) {
+		case time.Time:
+			bt = b
+		default:
+			s, _ := toString(b)
+			t, err := parseAnyTime(s)
+			if err != nil {
+				return 0, err
+			}
+			bt = t
+		}
+		switch {
+		case a.Before(bt):
+			return -1, nil
+		case a.After(bt):
+			return 1, nil
+		}
+		return 0, nil
+	case bool:
+		bb, ok := toBool(normalizeValue(b))
+		if !ok {
+			return 0, fmt.Errorf("invalid bool: %v", b)
+		}
+		switch {
+		case a == bb:
+			return 0, nil
+		case !a:
+			return -1, nil
+		}
+		return 1, nil
+	case int64:
+		if bi, ok := toInt64(normalizeValue(b)); ok {
+			switch {
+			case a < bi:
+				return -1, nil
+			case a > bi:
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return compareValues(float64(a), b)
+	case float64:
+		bf, ok := toFloat64(normalizeValue(b))
+		if !ok {
+			return 0, fmt.Errorf("invalid number: %v", b)
+		}
+		switch {
+		case a < bf:
+			return -1, nil
+		case a > bf:
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		bs, ok := toString(normalizeValue(b))
+		if !ok {
+			bs = fmt.Sprint(b)
+		}
+		return strings.Compare(a, bs), nil
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)), nil
+	}
+}
+
+// normalizeValue normalizes a value: integers become int64 (or float64 if they exceed it), other
+// numbers float64 and named types their underlying type.
+func normalizeValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if t, ok := v.(time.Time); ok {
+		return t
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return float64(rv.Uint())
+		}
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String:
+		if n, ok := v.(interface{ Int64() (int64, error) }); ok {
+			if i, err := n.Int64(); err == nil {
+				return i
+			}
+		}
+		if n, ok := v.(interface{ Float64() (float64, error) }); ok {
+			if f, err := n.Float64(); err == nil {
+				return f
+			}
+		}
+		return rv.String()
+	}
+	return v
+}
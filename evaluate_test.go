@@ -0,0 +1,138 @@
+package hyper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type evaluateAddress struct {
+	City string `json:"city"`
+}
+
+type evaluateOrder struct {
+	ID       string          `json:"id"`
+	Customer string          `hyper:"customer"`
+	Total    float64         `json:"total"`
+	Count    int             `json:"count"`
+	Paid     bool            `json:"paid"`
+	Created  time.Time       `json:"created"`
+	Address  evaluateAddress `json:"address"`
+	Note     *string         `json:"note"`
+}
+
+func TestApplyMeta(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC) }
+	orders := []evaluateOrder{
+		{ID: "1", Customer: "Alice", Total: 9.5, Count: 1, Paid: true, Created: day(1), Address: evaluateAddress{City: "Bonn"}},
+		{ID: "2", Customer: "Bob", Total: 20, Count: 2, Created: day(2), Address: evaluateAddress{City: "Berlin"}},
+		{ID: "3", Customer: "Carol", Total: 100, Count: 10, Paid: true, Created: day(3), Address: evaluateAddress{City: "Köln"}},
+		{ID: "4", Customer: "alfred", Total: 20, Count: 3, Created: day(4), Address: evaluateAddress{City: "Bonn"}},
+	}
+	ids := func(os []evaluateOrder) []string {
+		var res []string
+		for _, o := range os {
+			res = append(res, o.ID)
+		}
+		return res
+	}
+	tests := []struct {
+		name  string
+		meta  Meta
+		want  []string
+		total int
+	}{
+		{name: "all", meta: Meta{}, want: []string{"1", "2", "3", "4"}, total: 4},
+		{name: "eq number", meta: Meta{Filter: Filter{{Name: "total", Operator: FilterOperatorEquals, Value: "20"}}}, want: []string{"2", "4"}, total: 2},
+		{name: "numeric not lexical", meta: Meta{Filter: Filter{{Name: "count", Operator: FilterOperatorGreaterThen, Value: "2"}}}, want: []string{"3", "4"}, total: 2},
+		{name: "bool", meta: Meta{Filter: Filter{{Name: "paid", Operator: FilterOperatorEquals, Value: "true"}}}, want: []string{"1", "3"}, total: 2},
+		{name: "date", meta: Meta{Filter: Filter{{Name: "created", Operator: FilterOperatorGreaterThenOrEquals, Value: "2020-01-03"}}}, want: []string{"3", "4"}, total: 2},
+		{name: "between", meta: Meta{Filter: Filter{{Name: "total", Operator: FilterOperatorBetween, Value: []string{"10", "50"}}}}, want: []string{"2", "4"}, total: 2},
+		{name: "not between", meta: Meta{Filter: Filter{{Name: "total", Operator: FilterOperatorNotBetween, Value: []string{"10", "50"}}}}, want: []string{"1", "3"}, total: 2},
+		{name: "in", meta: Meta{Filter: Filter{{Name: "address.city", Operator: FilterOperatorIn, Value: []string{"Bonn", "Köln"}}}}, want: []string{"1", "3", "4"}, total: 3},
+		{name: "like substring", meta: Meta{Filter: Filter{{Name: "customer", Operator: FilterOperatorLike, Value: "AL"}}}, want: []string{"1", "4"}, total: 2},
+		{name: "like wildcard", meta: Meta{Filter: Filter{{Name: "customer", Operator: FilterOperatorLike, Value: "%l"}}}, want: []string{"3"}, total: 1},
+		{name: "not like", meta: Meta{Filter: Filter{{Name: "customer", Operator: FilterOperatorNotLike, Value: "a%"}}}, want: []string{"2", "3"}, total: 2},
+		{name: "missing field", meta: Meta{Filter: Filter{{Name: "note", Operator: FilterOperatorNotEquals, Value: "x"}}}, want: nil, total: 0},
+		{name: "not missing field", meta: Meta{Filter: Filter{FilterNot(FilterComponent{Name: "note", Operator: FilterOperatorEquals, Value: "x"})}}, want: []string{"1", "2", "3", "4"}, total: 4},
+		{name: "empty not", meta: Meta{Filter: Filter{FilterNot()}}, want: []string{"1", "2", "3", "4"}, total: 4},
+		{name: "or", meta: Meta{Filter: Filter{FilterOr(FilterComponent{Name: "paid", Operator: FilterOperatorEquals, Value: "true"}, FilterComponent{Name: "customer", Operator: FilterOperatorLike, Value: "bob"})}}, want: []string{"1", "2", "3"}, total: 3},
+		{name: "not", meta: Meta{Filter: Filter{FilterNot(FilterComponent{Name: "address.city", Operator: FilterOperatorEquals, Value: "Bonn"})}}, want: []string{"2", "3"}, total: 2},
+		{name: "nested", meta: Meta{Filter: Filter{FilterOr(FilterAnd(FilterComponent{Name: "paid", Operator: FilterOperatorEquals, Value: "true"}, FilterComponent{Name: "total", Operator: FilterOperatorGreaterThen, Value: "50"}), FilterNot(FilterComponent{Name: "count", Operator: FilterOperatorGreaterThenOrEquals, Value: "2"}))}}, want: []string{"1", "3"}, total: 2},
+		{name: "search", meta: Meta{Search: "BON"}, want: []string{"1", "4"}, total: 2},
+		{name: "sort", meta: Meta{Sort: Sort{{Name: "total", Order: SortOrderDescending}, {Name: "id", Order: SortOrderAscending}}}, want: []string{"3", "2", "4", "1"}, total: 4},
+		{name: "skip limit", meta: Meta{Sort: Sort{{Name: "created", Order: SortOrderDescending}}, Skip: 1, Limit: 2}, want: []string{"3", "2"}, total: 4},
+		{name: "after", meta: Meta{After: "2", Limit: 1}, want: []string{"3"}, total: 4},
+		{name: "after ignores skip", meta: Meta{After: "1", Skip: 1, Limit: 2}, want: []string{"2", "3"}, total: 4},
+		{name: "before", meta: Meta{Before: "4", Limit: 2}, want: []string{"2", "3"}, total: 4},
+		{name: "unknown cursor", meta: Meta{After: "9"}, want: nil, total: 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := append([]evaluateOrder(nil), orders...)
+			total, err := ApplyMeta(test.meta, &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.want, ids(got)) {
+				t.Errorf("want: %v, got: %v", test.want, ids(got))
+			}
+			if total != test.total {
+				t.Errorf("want: %d, got: %d", test.total, total)
+			}
+		})
+	}
+
+	if _, err := ApplyMeta(Meta{Filter: Filter{{Name: "total", Operator: FilterOperatorLessThen, Value: "many"}}}, &orders); err == nil {
+		t.Errorf("want: error for invalid number")
+	}
+}
+
+func TestApplyMetaItems(t *testing.T) {
+	items := Items{
+		{ID: "a", Properties: Properties{{Name: "price", Value: 12.0}}},
+		{ID: "b", Properties: Properties{{Name: "price", Value: 3.0}}},
+		{ID: "c"},
+	}
+	m := Meta{
+		Filter: Filter{{Name: "price", Operator: FilterOperatorGreaterThen, Value: "1"}},
+		Sort:   Sort{{Name: "price", Order: SortOrderAscending}},
+	}
+	if _, err := ApplyMeta(m, &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != "b" || items[1].ID != "a" {
+		t.Errorf("unexpected items: %v", items)
+	}
+}
+
+func TestApplyMetaInt64(t *testing.T) {
+	elems := []map[string]interface{}{
+		{"id": "a", "n": int64(1 << 53)},
+		{"id": "b", "n": int64(1<<53 + 1)},
+		{"id": "c", "n": uint8(7)},
+	}
+	tests := []struct {
+		value interface{}
+		want  []string
+	}{
+		{value: "9007199254740993", want: []string{"b"}},
+		{value: int64(1<<53 + 1), want: []string{"b"}},
+		{value: "7", want: []string{"c"}},
+		{value: 7.0, want: []string{"c"}},
+		{value: "7.5", want: nil},
+	}
+	for _, test := range tests {
+		got := append([]map[string]interface{}(nil), elems...)
+		if _, err := ApplyMeta(Meta{Filter: Filter{{Name: "n", Operator: FilterOperatorEquals, Value: test.value}}}, &got); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, el := range got {
+			ids = append(ids, el["id"].(string))
+		}
+		if !reflect.DeepEqual(test.want, ids) {
+			t.Errorf("%v: want: %v, got: %v", test.value, test.want, ids)
+		}
+	}
+}
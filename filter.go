@@ -1,9 +1,11 @@
 package hyper
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 func ParseFilter(url *url.URL) (Filter, error) {
@@ -59,8 +61,34 @@ func Not(accept func(FilterComponent) bool) func(FilterComponent) bool {
 	}
 }
 
+// ParseFilterComponent parses a filter component of the form name,operator,value[,value...]
+// or a group of the form and(...), or(...) or not(...) containing components separated by semicolons,
+// e.g. or(status,in,open,new;owner,eq,me).
+// A backslash escapes the following character, so names and values may contain commas (\,),
+// semicolons (\;), parentheses (\( and \)) and backslashes (\\).
+// A component starting with and(, or( or not( (before the first comma) is a group; groups with unbalanced
+// parentheses, trailing characters, no components or empty components are invalid. Any other component
+// is parsed as name,operator,value, so names like f(x) need no escaping.
 func ParseFilterComponent(rawFC string) (FilterComponent, error) {
-	parts := strings.Split(rawFC, ",")
+	op, raw, ok, err := cutFilterGroup(rawFC)
+	if err != nil {
+		return FilterComponent{}, err
+	}
+	if ok {
+		var fcs Filter
+		for _, rawSub := range splitFilterGroup(raw) {
+			if rawSub == "" {
+				return FilterComponent{}, fmt.Errorf("invalid filter group %s: empty component", rawFC)
+			}
+			fc, err := ParseFilterComponent(rawSub)
+			if err != nil {
+				return FilterComponent{}, err
+			}
+			fcs = append(fcs, fc)
+		}
+		return FilterComponent{Operator: op, Value: fcs}, nil
+	}
+	parts := splitEscaped(rawFC, ',')
 	if len(parts) < 3 {
 		return FilterComponent{}, fmt.Errorf("invalid filter component: %s", parts)
 	}
@@ -92,52 +120,299 @@ func ParseFilterComponent(rawFC string) (FilterComponent, error) {
 	}
 }
 
+// cutFilterGroup returns the operator and the components of a raw group. It reports false if
+// rawFC is no group, i.e. if it does not start with a group operator followed by an unescaped
+// parenthesis before the first unescaped comma.
+func cutFilterGroup(rawFC string) (FilterOperator, string, bool, error) {
+	i := groupParenthesis(rawFC)
+	if i < 0 {
+		return "", "", false, nil
+	}
+	op := FilterOperator(rawFC[:i])
+	if !op.IsGroup() {
+		return "", "", false, nil
+	}
+	switch end := closingParenthesis(rawFC, i); {
+	case end < 0:
+		return "", "", false, fmt.Errorf("invalid filter group %s: unbalanced parentheses", rawFC)
+	case end != len(rawFC)-1:
+		return "", "", false, fmt.Errorf("invalid filter group %s: unexpected characters after group: %s", rawFC, rawFC[end+1:])
+	case end == i+1:
+		return "", "", false, fmt.Errorf("invalid filter group %s: no components", rawFC)
+	}
+	return op, rawFC[i+1 : len(rawFC)-1], true, nil
+}
+
+// groupParenthesis returns the index of the unescaped parenthesis opening a group or -1 if
+// an unescaped comma comes first.
+func groupParenthesis(s string) int {
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == ',':
+			return -1
+		case s[i] == '(':
+			return i
+		}
+	}
+	return -1
+}
+
+// closingParenthesis returns the index of the parenthesis closing the one at open or -1.
+func closingParenthesis(s string, open int) int {
+	depth, escaped := 0, false
+	for i := open; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == '(':
+			depth++
+		case s[i] == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitFilterGroup splits the components of a group at the semicolons outside of nested groups.
+// The components remain escaped.
+func splitFilterGroup(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var parts []string
+	depth, start, escaped := 0, 0, false
+	for i, r := range raw {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ';' && depth == 0:
+			parts = append(parts, raw[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, raw[start:])
+}
+
+// splitEscaped splits s at every sep that is not escaped by a backslash and unescapes the parts.
+func splitEscaped(s string, sep rune) []string {
+	var parts []string
+	var part strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			part.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == sep:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteRune(r)
+		}
+	}
+	if escaped {
+		// a trailing backslash escapes nothing
+		part.WriteRune('\\')
+	}
+	return append(parts, part.String())
+}
+
+// escapeComponent escapes the separators and backslashes of a name or value of a filter or sort component.
+func escapeComponent(s string) string {
+	return componentEscaper.Replace(s)
+}
+
+var componentEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `;`, `\;`, `(`, `\(`, `)`, `\)`)
+
+// formatFilterComponent formats a FilterComponent as parsed by ParseFilterComponent.
+// Empty groups are ignored (see Meta) and formatted as the empty string.
+func formatFilterComponent(fc FilterComponent) string {
+	if fc.IsEmptyGroup() {
+		return ""
+	}
+	if fc.IsGroup() {
+		var subs []string
+		for _, sub := range fc.Components() {
+			if !sub.IsEmptyGroup() {
+				subs = append(subs, formatFilterComponent(sub))
+			}
+		}
+		return fmt.Sprintf("%s(%s)", fc.Operator, strings.Join(subs, ";"))
+	}
+	var vs []string
+	switch fv := fc.Value.(type) {
+	case []string:
+		for _, v := range fv {
+			vs = append(vs, escapeComponent(v))
+		}
+	case []interface{}:
+		for _, v := range fv {
+			vs = append(vs, escapeComponent(formatFilterValue(v)))
+		}
+	default:
+		vs = append(vs, escapeComponent(formatFilterValue(fc.Value)))
+	}
+	return fmt.Sprintf("%s,%s,%s", escapeComponent(fc.Name), fc.Operator, strings.Join(vs, ","))
+}
+
+// FilterAnd groups components that all have to match.
+func FilterAnd(fcs ...FilterComponent) FilterComponent {
+	return FilterComponent{Operator: FilterOperatorAnd, Value: Filter(fcs)}
+}
+
+// FilterOr groups components of which at least one has to match.
+func FilterOr(fcs ...FilterComponent) FilterComponent {
+	return FilterComponent{Operator: FilterOperatorOr, Value: Filter(fcs)}
+}
+
+// FilterNot groups components that must not all match.
+func FilterNot(fcs ...FilterComponent) FilterComponent {
+	return FilterComponent{Operator: FilterOperatorNot, Value: Filter(fcs)}
+}
+
 type FilterComponent struct {
 	Operator FilterOperator `json:"operator,omitempty"`
 	Name     string         `json:"name"`
 	Value    interface{}    `json:"value,omitempty"`
 }
 
+// IsGroup reports whether the component is an and, or or not group of Components.
+func (fc FilterComponent) IsGroup() bool {
+	return fc.Operator.IsGroup()
+}
+
+// IsEmptyGroup reports whether the component is a group without components other than empty groups.
+func (fc FilterComponent) IsEmptyGroup() bool {
+	if !fc.IsGroup() {
+		return false
+	}
+	for _, sub := range fc.Components() {
+		if !sub.IsEmptyGroup() {
+			return false
+		}
+	}
+	return true
+}
+
+// Components returns the components of a group.
+func (fc FilterComponent) Components() Filter {
+	f, _ := fc.Value.(Filter)
+	return f
+}
+
+// UnmarshalJSON decodes the value of a group as Filter.
+func (fc *FilterComponent) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Operator FilterOperator  `json:"operator"`
+		Name     string          `json:"name"`
+		Value    json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*fc = FilterComponent{Operator: raw.Operator, Name: raw.Name}
+	if len(raw.Value) == 0 {
+		return nil
+	}
+	if raw.Operator.IsGroup() {
+		var f Filter
+		if err := json.Unmarshal(raw.Value, &f); err != nil {
+			return err
+		}
+		fc.Value = f
+		return nil
+	}
+	return json.Unmarshal(raw.Value, &fc.Value)
+}
+
 func (fc FilterComponent) ValueStrings() []string {
 	ss := []string{}
 	switch v := fc.Value.(type) {
 	case []string:
 		return v
+	case []interface{}:
+		for _, e := range v {
+			ss = append(ss, formatFilterValue(e))
+		}
 	}
 	return ss
 }
 
+// ValueString returns the value as string. Typed values are formatted as in a query.
 func (fc FilterComponent) ValueString() string {
 	switch v := fc.Value.(type) {
 	case string:
 		return v
-	default:
+	case nil, []string, []interface{}, Filter:
 		return ""
+	default:
+		return formatFilterValue(v)
 	}
 }
 
+// ValueBool returns the value as bool. It returns false if the value is not a bool.
 func (fc FilterComponent) ValueBool() bool {
-	switch v := fc.Value.(type) {
-	case bool:
-		return v
-	case int:
-		switch v {
-		case 1:
-			return true
-		default:
-			return false
-		}
+	b, _ := toBool(fc.Value)
+	return b
+}
 
+// ValueInt64 returns the value as int64.
+func (fc FilterComponent) ValueInt64() (int64, error) {
+	i, ok := toInt64(fc.Value)
+	if !ok {
+		return 0, fmt.Errorf("filter %s: invalid integer: %v", fc.Name, fc.Value)
+	}
+	return i, nil
+}
+
+// ValueFloat64 returns the value as float64.
+func (fc FilterComponent) ValueFloat64() (float64, error) {
+	f, ok := toFloat64(fc.Value)
+	if !ok {
+		return 0, fmt.Errorf("filter %s: invalid number: %v", fc.Name, fc.Value)
+	}
+	return f, nil
+}
+
+// ValueTime returns the value as time.Time. Untyped values are parsed as datetime, date, month, week or time.
+func (fc FilterComponent) ValueTime() (time.Time, error) {
+	switch v := fc.Value.(type) {
+	case time.Time:
+		return v, nil
 	case string:
-		switch v {
-		case "true":
-			return true
-		default:
-			return false
+		for _, typ := range []string{TypeDatetime, TypeDate, TypeMonth, TypeWeek, TypeTime} {
+			if t, err := parseTimeValue(typ, v); err == nil {
+				return t, nil
+			}
 		}
-	default:
-		return false
 	}
+	return time.Time{}, fmt.Errorf("filter %s: invalid time: %v", fc.Name, fc.Value)
+}
+
+// Values returns the values of in, nin, bet and nbet or the single value of the other operators as list.
+func (fc FilterComponent) Values() []interface{} {
+	if fc.Value == nil {
+		return nil
+	}
+	return filterValues(fc.Value)
 }
 
 type FilterOperator string
@@ -155,9 +430,29 @@ const (
 	FilterOperatorNotLike             FilterOperator = "nlike"
 	FilterOperatorBetween             FilterOperator = "bet"
 	FilterOperatorNotBetween          FilterOperator = "nbet"
+	FilterOperatorAnd                 FilterOperator = "and"
+	FilterOperatorOr                  FilterOperator = "or"
+	FilterOperatorNot                 FilterOperator = "not"
 )
 
-func MakeFilterLink(fc FilterConfiguration, template string, currentFilter Filter, placeholder string) Link {
+// IsGroup reports whether the operator groups components.
+func (op FilterOperator) IsGroup() bool {
+	switch op {
+	case FilterOperatorAnd, FilterOperatorOr, FilterOperatorNot:
+		return true
+	}
+	return false
+}
+
+// MakeFilterLink builds the filter link. The group operators (FilterOperatorAnd, FilterOperatorOr and
+// FilterOperatorNot) accepted in addition to the components of the configuration are advertised as
+// Options of the filter parameter.
+func MakeFilterLink(fc FilterConfiguration, template string, currentFilter Filter, placeholder string, groups ...FilterOperator) Link {
+	var options SelectOptions
+	for _, op := range groups {
+		c := FilterOperatorConfigurationFor(op)
+		options = append(options, SelectOption{Label: c.Label, Value: c.Operator})
+	}
 	return Link{
 		Rel:      "filter",
 		Template: template,
@@ -166,6 +461,7 @@ func MakeFilterLink(fc FilterConfiguration, template string, currentFilter Filte
 				Name:        "filter",
 				Type:        "filter",
 				Components:  fc,
+				Options:     options,
 				Value:       currentFilter,
 				Placeholder: placeholder,
 			},
@@ -187,6 +483,210 @@ func (c FilterConfiguration) Less(i, j int) bool {
 	return c[i].Label < c[j].Label
 }
 
+// FindOne returns the configuration of the filter component with the name.
+func (c FilterConfiguration) FindOne(name string) (FilterComponentConfiguration, bool) {
+	for _, cc := range c {
+		if cc.Name == name {
+			return cc, true
+		}
+	}
+	return FilterComponentConfiguration{}, false
+}
+
+// Validate checks the components of a Filter against the configuration: the name has to be configured,
+// the operator has to be one of its Operators (or of the operators for its Type if there are none)
+// and the values have to match its Type and Options. Like a select, a filter with Options requires Multiple
+// to accept more than one value for in and nin. Groups are only valid if their operator is one of groups
+// and all of their components are valid.
+// It returns nil if the Filter is valid.
+func (c FilterConfiguration) Validate(f Filter, groups ...FilterOperator) Errors {
+	var errs Errors
+	for _, fc := range f {
+		if fc.IsGroup() {
+			if !hasFilterOperator(groups, fc.Operator) {
+				errs = append(errs, Error{Name: "filter", Code: ErrorCodeOption, Message: fmt.Sprintf("filter groups with %s are not supported", fc.Operator)})
+				continue
+			}
+			errs = append(errs, c.Validate(fc.Components(), groups...)...)
+			continue
+		}
+		cc, ok := c.FindOne(fc.Name)
+		if !ok {
+			errs = append(errs, Error{Name: fc.Name, Code: ErrorCodeOption, Message: fmt.Sprintf("filter by %s is not supported", fc.Name)})
+			continue
+		}
+		errs = append(errs, cc.Validate(fc)...)
+	}
+	return errs
+}
+
+// Validate checks a FilterComponent against the configuration.
+func (cc FilterComponentConfiguration) Validate(fc FilterComponent) Errors {
+	p := Parameter{Name: cc.Name, Label: cc.Label, Type: cc.Type, Options: cc.Options}
+	if p.Type == "" {
+		p.Type = TypeText
+	}
+	label := parameterLabel(p)
+	if !cc.allows(fc.Operator) {
+		return Errors{parameterError(p, ErrorCodeOption, "%s does not support the operator %s", label, fc.Operator)}
+	}
+	values, isMultiple := multipleValues(fc.Value)
+	if !isMultiple {
+		values = []interface{}{fc.Value}
+	}
+	switch fc.Operator {
+	case FilterOperatorIn, FilterOperatorNotIn:
+		if len(values) > 1 && len(cc.Options) > 0 && !cc.Multiple {
+			return Errors{parameterError(p, ErrorCodeMultiple, "%s does not allow multiple values", label)}
+		}
+	case FilterOperatorBetween, FilterOperatorNotBetween:
+		if len(values) != 2 {
+			return Errors{parameterError(p, ErrorCodeType, "%s requires two values", label)}
+		}
+	case FilterOperatorLike, FilterOperatorNotLike:
+		// patterns are texts
+		p.Type, p.Options = TypeText, nil
+		fallthrough
+	default:
+		if isMultiple {
+			return Errors{parameterError(p, ErrorCodeMultiple, "%s does not allow multiple values", label)}
+		}
+	}
+	var errs Errors
+	for _, v := range values {
+		errs = append(errs, validateValue(p, v)...)
+	}
+	return errs
+}
+
+// Typed returns the Filter with the values converted according to the Type of their configuration:
+// int64 for integers, float64 for numbers, bool for bools, time.Time for the time types and
+// the value of the matching option for filters with Options. Patterns of like and nlike and
+// components without configuration are left as they are. The components of groups are converted as well.
+func (c FilterConfiguration) Typed(f Filter) (Filter, Errors) {
+	var errs Errors
+	res := make(Filter, len(f))
+	for i, fc := range f {
+		res[i] = fc
+		if fc.IsGroup() {
+			sub, subErrs := c.Typed(fc.Components())
+			errs = append(errs, subErrs...)
+			res[i].Value = sub
+			continue
+		}
+		cc, ok := c.FindOne(fc.Name)
+		if !ok {
+			continue
+		}
+		tfc, tErrs := cc.Typed(fc)
+		if len(tErrs) > 0 {
+			errs = append(errs, tErrs...)
+			continue
+		}
+		res[i] = tfc
+	}
+	return res, errs
+}
+
+// Typed returns the FilterComponent with the values converted according to the configuration.
+func (cc FilterComponentConfiguration) Typed(fc FilterComponent) (FilterComponent, Errors) {
+	switch fc.Operator {
+	case FilterOperatorLike, FilterOperatorNotLike:
+		return fc, nil
+	}
+	vs, isMultiple := multipleValues(fc.Value)
+	if !isMultiple {
+		v, errs := cc.typedValue(fc.Value)
+		if len(errs) > 0 {
+			return fc, errs
+		}
+		fc.Value = v
+		return fc, nil
+	}
+	tvs := make([]interface{}, len(vs))
+	for i, v := range vs {
+		tv, errs := cc.typedValue(v)
+		if len(errs) > 0 {
+			return fc, errs
+		}
+		tvs[i] = tv
+	}
+	fc.Value = tvs
+	return fc, nil
+}
+
+func (cc FilterComponentConfiguration) typedValue(v interface{}) (interface{}, Errors) {
+	p := Parameter{Name: cc.Name, Label: cc.Label}
+	if len(cc.Options) > 0 {
+		if o, ok := findOption(cc.Options, v); ok {
+			return o.Value, nil
+		}
+		return nil, Errors{parameterError(p, ErrorCodeOption, "%s is not a valid option: %v", parameterLabel(p), v)}
+	}
+	if s, ok := v.(string); ok && isTimeType(cc.Type) && cc.Type != TypeTime {
+		// typed values are formatted in RFC 3339 (see formatFilterValue)
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, nil
+		}
+	}
+	tv, err := typedValue(cc.Type, v)
+	if err != nil {
+		return nil, Errors{parameterError(p, ErrorCodeType, "%s: %v", parameterLabel(p), err)}
+	}
+	return tv, nil
+}
+
+// ParseTypedFilter parses the Filter of the url and converts its values according to the configuration.
+func ParseTypedFilter(url *url.URL, c FilterConfiguration) (Filter, error) {
+	f, err := ParseFilter(url)
+	if err != nil {
+		return nil, err
+	}
+	f, errs := c.Typed(f)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return f, nil
+}
+
+// formatFilterValue formats a (typed) filter value for a query. Times of day (in year 0) are
+// formatted as time, all other times in RFC 3339, which the configurations of every other
+// time type accept as well.
+func formatFilterValue(v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		if t.Year() == 0 {
+			return t.Format("15:04:05.999999999")
+		}
+		return t.Format(time.RFC3339Nano)
+	}
+	if s, ok := toString(v); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func (cc FilterComponentConfiguration) allows(op FilterOperator) bool {
+	ocs := cc.Operators
+	if len(ocs) == 0 {
+		ocs = FilterOperatorConfigurationsForBaseType(cc.Type)
+	}
+	for _, oc := range ocs {
+		if oc.Operator == op {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFilterOperator(ops []FilterOperator, op FilterOperator) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
 type FilterComponentConfiguration struct {
 	Label       string                        `json:"label,omitempty"`
 	Description string                        `json:"description,omitempty"`
@@ -219,6 +719,9 @@ var configs = map[FilterOperator]FilterOperatorConfiguration{
 	FilterOperatorNotIn:               {Label: "not in", Operator: FilterOperatorNotIn},
 	FilterOperatorLike:                {Label: "like", Operator: FilterOperatorLike},
 	FilterOperatorNotLike:             {Label: "not like", Operator: FilterOperatorNotLike},
+	FilterOperatorAnd:                 {Label: "and", Operator: FilterOperatorAnd},
+	FilterOperatorOr:                  {Label: "or", Operator: FilterOperatorOr},
+	FilterOperatorNot:                 {Label: "not", Operator: FilterOperatorNot},
 }
 
 func FilterOperatorConfigurationFor(op FilterOperator) FilterOperatorConfiguration {
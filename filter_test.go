@@ -0,0 +1,128 @@
+package hyper
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterConfigurationTyped(t *testing.T) {
+	c := FilterConfiguration{
+		{Name: "count", Type: TypeInteger},
+		{Name: "total", Type: TypeNumber},
+		{Name: "paid", Type: TypeBool},
+		{Name: "created", Type: TypeDate},
+		{Name: "updated", Type: TypeDatetime},
+		{Name: "month", Type: TypeMonth},
+		{Name: "week", Type: TypeWeek},
+		{Name: "at", Type: TypeTime},
+		{Name: "state", Type: TypeSelect, Options: SelectOptions{{Label: "open", Value: 1}, {Label: "closed", Value: 2}}},
+		{Name: "name", Type: TypeText},
+	}
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		query string
+		want  interface{}
+	}{
+		{query: "count,eq,42", want: int64(42)},
+		{query: "count,in,1,2", want: []interface{}{int64(1), int64(2)}},
+		{query: "total,bet,1.5,10", want: []interface{}{1.5, float64(10)}},
+		{query: "paid,eq,true", want: true},
+		{query: "created,geq,2020-01-02", want: date(2020, 1, 2)},
+		{query: "updated,lt,2020-01-02T10:30:00Z", want: time.Date(2020, 1, 2, 10, 30, 0, 0, time.UTC)},
+		{query: "updated,eq,2020-01-02T00:00:00Z", want: date(2020, 1, 2)},
+		{query: "month,eq,2020-02", want: date(2020, 2, 1)},
+		{query: "week,eq,2020-W02", want: date(2020, 1, 6)},
+		{query: "at,gt,10:30", want: time.Date(0, 1, 1, 10, 30, 0, 0, time.UTC)},
+		{query: "state,in,2", want: []interface{}{2}},
+		{query: "name,eq,42", want: "42"},
+		{query: "count,like,4%", want: "4%"},
+		{query: "other,eq,42", want: "42"},
+		{query: "or(count,eq,1;not(paid,eq,true))", want: Filter{
+			{Name: "count", Operator: FilterOperatorEquals, Value: int64(1)},
+			FilterNot(FilterComponent{Name: "paid", Operator: FilterOperatorEquals, Value: true}),
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			u, _ := url.Parse("/?filter=" + url.QueryEscape(test.query))
+			f, err := ParseTypedFilter(u, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f[0].Value; !reflect.DeepEqual(test.want, got) {
+				t.Errorf("want: %#v, got: %#v", test.want, got)
+			}
+
+			// formatted typed values parse to the same values
+			q, _ := url.Parse("/" + Meta{Filter: f}.Query())
+			rf, err := ParseTypedFilter(q, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(f, rf) {
+				t.Errorf("want: %#v, got: %#v", f, rf)
+			}
+		})
+	}
+}
+
+func TestFilterConfigurationTypedErrors(t *testing.T) {
+	c := FilterConfiguration{
+		{Name: "count", Type: TypeInteger},
+		{Name: "created", Type: TypeDate},
+		{Name: "state", Type: TypeSelect, Options: SelectOptions{{Value: "open"}}},
+	}
+	f := Filter{
+		{Name: "count", Operator: FilterOperatorIn, Value: []string{"1", "x"}},
+		{Name: "created", Operator: FilterOperatorEquals, Value: "2020-13-01"},
+		{Name: "state", Operator: FilterOperatorEquals, Value: "lost"},
+	}
+	_, errs := c.Typed(f)
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Name+":"+e.Code)
+	}
+	want := []string{"count:" + ErrorCodeType, "created:" + ErrorCodeType, "state:" + ErrorCodeOption}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestFilterComponentValues(t *testing.T) {
+	fc := FilterComponent{Name: "a", Value: "12"}
+	if i, err := fc.ValueInt64(); err != nil || i != 12 {
+		t.Errorf("want: 12, got: %d (%v)", i, err)
+	}
+	if f, err := fc.ValueFloat64(); err != nil || f != 12 {
+		t.Errorf("want: 12, got: %v (%v)", f, err)
+	}
+	if _, err := fc.ValueTime(); err == nil {
+		t.Errorf("want: error, got: nil")
+	}
+	fc.Value = "2020-01-02"
+	if tm, err := fc.ValueTime(); err != nil || !tm.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("want: 2020-01-02, got: %v (%v)", tm, err)
+	}
+	if _, err := fc.ValueInt64(); err == nil {
+		t.Errorf("want: error, got: nil")
+	}
+	for v, want := range map[interface{}]bool{"true": true, "1": true, "false": false, true: true, int64(0): false, "x": false} {
+		if got := (FilterComponent{Value: v}).ValueBool(); got != want {
+			t.Errorf("%v: want: %t, got: %t", v, want, got)
+		}
+	}
+	fc.Value = []interface{}{int64(1), 2.5}
+	if got, want := fc.ValueStrings(), []string{"1", "2.5"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+	fc.Value = 2.5
+	if got := fc.ValueString(); got != "2.5" {
+		t.Errorf("want: 2.5, got: %s", got)
+	}
+	fc.Value = time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	if got, want := fc.ValueString(), "2020-01-02T00:00:00Z"; got != want {
+		t.Errorf("want: %s, got: %s", want, got)
+	}
+}
@@ -0,0 +1,243 @@
+package hyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ContentTypeHAL is the media type of the Hypertext Application Language.
+// See: https://tools.ietf.org/html/draft-kelly-json-hal
+const ContentTypeHAL = "application/hal+json"
+
+const (
+	halLinks    = "_links"
+	halEmbedded = "_embedded"
+	halErrors   = "errors"
+)
+
+// HALLink is a link object as defined by HAL.
+type HALLink struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Hreflang  string `json:"hreflang,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// ItemToHAL converts an Item into a HAL resource.
+//
+// The mapping is as follows:
+//   - Properties become top-level fields; the fields of a JSON object in Data are merged in as well.
+//   - Links are grouped by Rel in _links; links with a Template become templated links.
+//   - Items are grouped by Rel in _embedded (items without Rel are embedded as "item").
+//   - Errors are embedded as "errors" in the style of vnd.error (message, logref, path).
+//   - Actions are lossy: HAL has no notion of forms, so every Action becomes a link
+//     (href and title) grouped by its Rel. Method, Encoding and Parameters are dropped.
+//   - Label, Description, ID, Type and Render have no HAL counterpart and are dropped.
+func ItemToHAL(i Item) map[string]interface{} {
+	res := map[string]interface{}{}
+	if len(i.Data) > 0 {
+		data := map[string]interface{}{}
+		if err := json.Unmarshal(i.Data, &data); err == nil {
+			for k, v := range data {
+				res[k] = v
+			}
+		}
+	}
+	for _, p := range i.Properties {
+		res[p.Name] = p.Value
+	}
+
+	links := map[string][]HALLink{}
+	var linkRels []string
+	addLink := func(rel string, l HALLink) {
+		if _, ok := links[rel]; !ok {
+			linkRels = append(linkRels, rel)
+		}
+		links[rel] = append(links[rel], l)
+	}
+	for _, l := range i.Links {
+		hl := HALLink{
+			Href:     l.Href,
+			Type:     l.Type,
+			Title:    l.Label,
+			Hreflang: l.Language,
+		}
+		if l.Template != "" {
+			hl.Href = templateHref(l)
+			hl.Templated = true
+		}
+		addLink(l.Rel, hl)
+	}
+	for _, a := range i.Actions {
+		addLink(a.Rel, HALLink{Href: a.Href, Title: a.Label})
+	}
+	if len(links) > 0 {
+		hls := map[string]interface{}{}
+		for _, rel := range linkRels {
+			if ls := links[rel]; len(ls) == 1 {
+				hls[rel] = ls[0]
+			} else {
+				hls[rel] = ls
+			}
+		}
+		res[halLinks] = hls
+	}
+
+	embedded := map[string][]interface{}{}
+	for _, sub := range i.Items {
+		rel := sub.Rel
+		if rel == "" {
+			rel = RelItem
+		}
+		embedded[rel] = append(embedded[rel], ItemToHAL(sub))
+	}
+	for _, e := range i.Errors {
+		ve := map[string]interface{}{"message": e.Message}
+		if e.Code != "" {
+			ve["logref"] = e.Code
+		}
+		if e.Name != "" {
+			ve["path"] = e.Name
+		}
+		embedded[halErrors] = append(embedded[halErrors], ve)
+	}
+	if len(embedded) > 0 {
+		hes := map[string]interface{}{}
+		for rel, es := range embedded {
+			if len(es) == 1 && rel != halErrors {
+				hes[rel] = es[0]
+			} else {
+				hes[rel] = es
+			}
+		}
+		res[halEmbedded] = hes
+	}
+	return res
+}
+
+// HALToItem converts a HAL resource into an Item.
+// Top-level fields become Properties (ordered by name), _links become Links
+// and _embedded resources become sub-Items with the embedding rel as Rel.
+// Embedded "errors" in the style of vnd.error become Errors.
+func HALToItem(r map[string]interface{}) (Item, error) {
+	i := Item{}
+	var names []string
+	for k := range r {
+		if k != halLinks && k != halEmbedded {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		i.AddProperty(Property{Name: n, Value: r[n]})
+	}
+
+	if raw, ok := r[halLinks]; ok {
+		ls, ok := raw.(map[string]interface{})
+		if !ok {
+			return Item{}, fmt.Errorf("hal: invalid %s", halLinks)
+		}
+		for _, rel := range sortedKeys(ls) {
+			for _, o := range halObjects(ls[rel]) {
+				hl := HALLink{}
+				if err := remarshal(o, &hl); err != nil {
+					return Item{}, fmt.Errorf("hal: invalid link %s: %v", rel, err)
+				}
+				l := Link{
+					Rel:      rel,
+					Label:    hl.Title,
+					Type:     hl.Type,
+					Language: hl.Hreflang,
+				}
+				if hl.Templated {
+					l.Template = hl.Href
+				} else {
+					l.Href = hl.Href
+				}
+				i.AddLink(l)
+			}
+		}
+	}
+
+	if raw, ok := r[halEmbedded]; ok {
+		es, ok := raw.(map[string]interface{})
+		if !ok {
+			return Item{}, fmt.Errorf("hal: invalid %s", halEmbedded)
+		}
+		for _, rel := range sortedKeys(es) {
+			for _, o := range halObjects(es[rel]) {
+				if rel == halErrors {
+					if _, isError := o["message"]; isError {
+						e := Error{}
+						e.Message, _ = o["message"].(string)
+						e.Code, _ = o["logref"].(string)
+						e.Name, _ = o["path"].(string)
+						i.Errors = append(i.Errors, e)
+						continue
+					}
+				}
+				sub, err := HALToItem(o)
+				if err != nil {
+					return Item{}, err
+				}
+				if rel != RelItem {
+					sub.Rel = rel
+				}
+				i.AddItem(sub)
+			}
+		}
+	}
+	return i, nil
+}
+
+// EncodeHAL encodes an Item as HAL.
+func EncodeHAL(w io.Writer, i Item) error {
+	return json.NewEncoder(w).Encode(ItemToHAL(i))
+}
+
+// DecodeHAL decodes a HAL resource into an Item.
+func DecodeHAL(r io.Reader) (Item, error) {
+	res := map[string]interface{}{}
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return Item{}, err
+	}
+	return HALToItem(res)
+}
+
+// halObjects normalizes a single object or an array of objects.
+func halObjects(v interface{}) []map[string]interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{v}
+	case []interface{}:
+		var os []map[string]interface{}
+		for _, x := range v {
+			if o, ok := x.(map[string]interface{}); ok {
+				os = append(os, o)
+			}
+		}
+		return os
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func remarshal(in interface{}, out interface{}) error {
+	bs, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, out)
+}
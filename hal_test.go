@@ -0,0 +1,88 @@
+package hyper
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestItemToHAL(t *testing.T) {
+	i := Item{
+		Properties: Properties{{Name: "total", Value: 30.0}},
+		Links: Links{
+			{Rel: RelSelf, Href: "/orders"},
+			{Rel: RelSearch, Href: "/orders?limit=10", Template: "{?search}"},
+		},
+		Actions: Actions{{Rel: "create", Label: "Create", Href: "/orders", Method: MethodPOST}},
+		Items: Items{
+			{Rel: "orders", Properties: Properties{{Name: "id", Value: "1"}}},
+			{Rel: "orders", Properties: Properties{{Name: "id", Value: "2"}}},
+		},
+		Errors: Errors{{Message: "boom", Code: "fail", Name: "x"}},
+	}
+	bs, err := json.Marshal(ItemToHAL(i))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	json.Unmarshal(bs, &got)
+	want := map[string]interface{}{}
+	json.Unmarshal([]byte(`{
+		"total": 30,
+		"_links": {
+			"self": {"href": "/orders"},
+			"search": {"href": "/orders?limit=10{&search}", "templated": true},
+			"create": {"href": "/orders", "title": "Create"}
+		},
+		"_embedded": {
+			"orders": [{"id": "1"}, {"id": "2"}],
+			"errors": [{"message": "boom", "logref": "fail", "path": "x"}]
+		}
+	}`), &want)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+func TestClientFetchHAL(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// hyper-items are preferred over HAL, which is preferred over plain JSON
+		accept := r.Header.Get(HeaderAccept)
+		mrs := ParseAccept(accept)
+		if len(mrs) == 0 || mrs[0].Type+"/"+mrs[0].Subtype != ContentTypeHyperItem {
+			t.Errorf("unexpected accept: %s", accept)
+		}
+		hyper, hal, plain := AcceptQuality(mrs, ContentTypeHyperItem), AcceptQuality(mrs, ContentTypeHAL), AcceptQuality(mrs, ContentTypeJSON)
+		if !(hyper > hal && hal > plain && plain > 0) {
+			t.Errorf("unexpected accept: %s", accept)
+		}
+		w.Header().Set(HeaderContentType, ContentTypeHAL)
+		w.Write([]byte(`{
+			"name": "foo",
+			"_links": {"self": {"href": "/foo"}, "find": {"href": "/foo{?q}", "templated": true}},
+			"_embedded": {"item": [{"name": "bar"}], "owner": {"name": "baz"}}
+		}`))
+	}))
+	defer s.Close()
+
+	got, err := NewClient().Fetch(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Item{
+		Properties: Properties{{Name: "name", Value: "foo"}},
+		Links: Links{
+			{Rel: "find", Template: "/foo{?q}"},
+			{Rel: RelSelf, Href: "/foo"},
+		},
+		Items: Items{
+			{Properties: Properties{{Name: "name", Value: "bar"}}},
+			{Rel: "owner", Properties: Properties{{Name: "name", Value: "baz"}}},
+		},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant: %#v\ngot:  %#v", want, got)
+	}
+}
@@ -0,0 +1,570 @@
+package hyper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// HTMLHandler wraps next so that Write and WriteFor render HTML pages instead of hyper-items
+// whenever the request prefers text/html (e.g. when browsing the API with a browser).
+// Write recognizes the response writer passed to next (and writers wrapping it that implement
+// Unwrap() http.ResponseWriter); WriteFor works with any writer as it checks the request.
+//
+// As HTML forms only support GET and POST, actions with other methods are rendered as POST forms
+// with a hidden NameMethod field. See HTMLMethodOverride for restoring their method.
+func HTMLHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if PrefersHTML(r) {
+			r = r.WithContext(context.WithValue(r.Context(), htmlKey{}, true))
+			w = &htmlResponseWriter{ResponseWriter: w}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type htmlKey struct{}
+
+// htmlResponseWriter marks the response writer of a request preferring text/html.
+type htmlResponseWriter struct {
+	http.ResponseWriter
+}
+
+// Unwrap returns the wrapped response writer (see http.ResponseController).
+func (w *htmlResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush flushes the wrapped response writer if it supports flushing.
+func (w *htmlResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// isHTMLWriter reports whether w is (or wraps) the response writer of a HTMLHandler for a
+// request preferring text/html.
+func isHTMLWriter(w http.ResponseWriter) bool {
+	for {
+		switch ww := w.(type) {
+		case *htmlResponseWriter:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = ww.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// IsHTMLRequest reports whether the request passed a HTMLHandler and prefers text/html.
+func IsHTMLRequest(r *http.Request) bool {
+	ok, _ := r.Context().Value(htmlKey{}).(bool)
+	return ok
+}
+
+// NameMethod is the name of the hidden field carrying the method of an action in HTML forms.
+const NameMethod = "@method"
+
+// HTMLMethodOverride wraps next so that POST form submissions of HTML pages rendered for actions
+// with the methods PUT, PATCH or DELETE reach next with that method (taken from the NameMethod field).
+// It has to be used within a HTMLHandler, e.g. HTMLHandler(HTMLMethodOverride(mux)), and only
+// overrides the method of requests preferring text/html that are not marked as cross-site by
+// their Origin or Sec-Fetch-Site header.
+//
+// Beware: any site can submit a form by POST without a CORS preflight, so with the override a
+// cross-site form could reach PUT, PATCH and DELETE handlers from browsers that send neither header.
+// Protect these handlers against CSRF (e.g. with tokens or SameSite cookies) before opting in.
+func HTMLMethodOverride(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && IsHTMLRequest(r) && !isCrossSite(r) {
+			overrideMethod(r)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isCrossSite reports whether the Origin or Sec-Fetch-Site header shows that the request was
+// issued by a page of another origin.
+func isCrossSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err != nil || u.Host != r.Host
+}
+
+// overrideMethod sets the method of a POST form submission to the value of its NameMethod field
+// if that is PUT, PATCH or DELETE. The body is left readable.
+func overrideMethod(r *http.Request) {
+	var method string
+	ct := r.Header.Get(HeaderContentType)
+	switch {
+	case strings.HasPrefix(ct, ContentTypeURLEncoded):
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return
+		}
+		method = values.Get(NameMethod)
+	case strings.HasPrefix(ct, ContentTypeMultipartFormData):
+		// the parsed form is reused by ExtractCommand
+		if err := r.ParseMultipartForm(defaultMaxMemory); err != nil {
+			return
+		}
+		if vs := r.MultipartForm.Value[NameMethod]; len(vs) > 0 {
+			method = vs[0]
+		}
+	}
+	switch method = strings.ToUpper(method); method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		r.Method = method
+	}
+}
+
+// WriteHTML writes a hyper-item as a navigable HTML page with the given status code.
+func WriteHTML(w http.ResponseWriter, status int, i Item) {
+	buf := bytes.Buffer{}
+	if err := RenderHTML(&buf, i); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(HeaderContentType, ContentTypeHTMLUTF8)
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// RenderHTML renders a hyper-item as HTML page.
+func RenderHTML(w io.Writer, i Item) error {
+	return htmlTemplate.ExecuteTemplate(w, "page", i)
+}
+
+var htmlTemplate = template.Must(template.New("html").Funcs(template.FuncMap{
+	"title":       htmlTitle,
+	"visible":     isVisible,
+	"transclude":  isTranscluded,
+	"property":    htmlPropertyValue,
+	"form":        htmlActionForm,
+	"linkForm":    htmlLinkForm,
+	"ref":         htmlItemRef,
+	"isImage":     func(l Link) bool { return strings.HasPrefix(l.Type, "image/") },
+	"isSelected":  htmlIsSelected,
+	"isChecked":   htmlIsChecked,
+	"hasTemplate": func(l Link) bool { return l.Template != "" },
+	"isSet":       func(v interface{}) bool { return v != nil },
+}).Parse(htmlTemplateText))
+
+const htmlTemplateText = `
+{{- define "page" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{title .}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: .25em .5em; border-bottom: 1px solid #ddd; }
+section.item { margin: 1em 0; }
+ul.items > li > section.item { border-left: 3px solid #ddd; padding-left: 1em; }
+form { margin: 1em 0; padding: .5em 1em; border: 1px solid #ddd; }
+.error { color: #b00; }
+.invalid input, .invalid select, .invalid textarea { border-color: #b00; }
+</style>
+</head>
+<body>
+{{template "item" .}}
+</body>
+</html>
+{{- end}}
+
+{{- define "item"}}
+<section class="item"{{with .ID}} id="{{.}}"{{end}}>
+{{- with title .}}
+<h1>{{.}}</h1>
+{{- end}}
+{{- with .Description}}
+<p>{{.}}</p>
+{{- end}}
+{{- with .Errors}}
+<ul class="errors">
+{{- range .}}
+<li class="error">{{with .Label}}<strong>{{.}}:</strong> {{end}}{{.Message}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Properties}}
+<table class="properties">
+{{- range .Properties}}{{if visible .Render}}
+<tr><th>{{if .Label}}{{.Label}}{{else}}{{.Name}}{{end}}</th><td>{{property .}}</td></tr>
+{{- end}}{{end}}
+</table>
+{{- end}}
+{{- if .Links}}
+<nav class="links">
+<ul>
+{{- range .Links}}{{if visible .Render}}
+<li>{{template "link" .}}</li>
+{{- end}}{{end}}
+</ul>
+</nav>
+{{- end}}
+{{- $errs := .Errors}}
+{{- range .Actions}}{{if visible .Render}}
+{{template "form" (form . $errs)}}
+{{- end}}{{end}}
+{{- if .Items}}
+<ul class="items">
+{{- range .Items}}{{if visible .Render}}
+<li>{{if transclude .Render}}{{template "item" .}}{{else}}{{template "ref" (ref .)}}{{end}}</li>
+{{- end}}{{end}}
+</ul>
+{{- end}}
+</section>
+{{- end}}
+
+{{- define "link"}}
+{{- if transclude .Render}}
+{{- if isImage .}}<img src="{{.Href}}" alt="{{.Label}}">{{else}}<object data="{{.Href}}"{{with .Type}} type="{{.}}"{{end}}>{{.Label}}</object>{{end}}
+{{- else if hasTemplate .}}
+{{- with linkForm .}}{{template "form" .}}{{else}}<code>{{.Template}}</code>{{end}}
+{{- else -}}
+<a href="{{.Href}}" rel="{{.Rel}}"{{with .Type}} type="{{.}}"{{end}}{{with .Language}} hreflang="{{.}}"{{end}}{{with .Download}} download="{{.}}"{{end}}>{{if .Label}}{{.Label}}{{else}}{{.Rel}}{{end}}</a>
+{{- end}}
+{{- end}}
+
+{{- define "ref" -}}
+{{if .Href}}<a href="{{.Href}}">{{.Label}}</a>{{else}}{{.Label}}{{end}}
+{{- end}}
+
+{{- define "form"}}
+<form action="{{.Action}}" method="{{.Method}}"{{with .Enctype}} enctype="{{.}}"{{end}}>
+{{- with .Label}}
+<h2>{{.}}</h2>
+{{- end}}
+{{- with .Description}}
+<p>{{.}}</p>
+{{- end}}
+{{- range .Inputs}}{{template "input" .}}{{end}}
+<button type="submit">{{.OK}}</button>
+{{- with .Reset}} <button type="reset">{{.}}</button>{{end}}
+</form>
+{{- end}}
+
+{{- define "input"}}
+{{- if eq .Control "hidden"}}
+<input type="hidden" name="{{.Name}}" value="{{.Value}}">
+{{- else if eq .Control "fieldset"}}
+<fieldset{{if .Errors}} class="invalid"{{end}}>
+<legend>{{.Label}}</legend>
+{{- range .Inputs}}{{template "input" .}}{{end}}
+{{- range .Errors}}
+<div class="error">{{.Message}}</div>
+{{- end}}
+</fieldset>
+{{- else}}
+<div class="parameter{{if .Errors}} invalid{{end}}">
+<label>{{.Label}}{{if .Parameter.Required}} *{{end}}
+{{- if eq .Control "select"}}
+<select name="{{.Name}}"{{template "attributes" .}}>
+{{- if not .Parameter.Required}}
+<option value=""></option>
+{{- end}}
+{{- $in := .}}
+{{- range .Parameter.Options}}
+{{- if .Options}}
+<optgroup label="{{.Label}}">
+{{- range .Options}}
+<option value="{{.Value}}"{{if isSelected $in .}} selected{{end}}>{{if .Label}}{{.Label}}{{else}}{{.Value}}{{end}}</option>
+{{- end}}
+</optgroup>
+{{- else}}
+<option value="{{.Value}}"{{if isSelected $in .}} selected{{end}}>{{if .Label}}{{.Label}}{{else}}{{.Value}}{{end}}</option>
+{{- end}}
+{{- end}}
+</select>
+{{- else if eq .Control "textarea"}}
+<textarea name="{{.Name}}"{{with .Parameter.Rows}} rows="{{.}}"{{end}}{{with .Parameter.Cols}} cols="{{.}}"{{end}}{{with .Parameter.Wrap}} wrap="{{.}}"{{end}}{{template "attributes" .}}>{{.Value}}</textarea>
+{{- else if eq .Control "checkbox"}}
+<input type="checkbox" name="{{.Name}}" value="true"{{if isChecked .}} checked{{end}}{{template "attributes" .}}>
+{{- else}}
+<input type="{{.Control}}" name="{{.Name}}"{{with .Value}} value="{{.}}"{{end}}{{template "attributes" .}}>
+{{- end}}
+</label>
+{{- with .Parameter.Description}}
+<small>{{.}}</small>
+{{- end}}
+{{- range .Errors}}
+<div class="error">{{.Message}}</div>
+{{- end}}
+</div>
+{{- end}}
+{{- end}}
+
+{{- define "attributes" -}}
+{{with .Parameter}}
+{{- if .Required}} required{{end}}
+{{- if .ReadOnly}} readonly{{end}}
+{{- if .Multiple}} multiple{{end}}
+{{- with .Placeholder}} placeholder="{{.}}"{{end}}
+{{- with .Pattern}} pattern="{{.}}"{{end}}
+{{- if isSet .Min}} min="{{.Min}}"{{end}}
+{{- if isSet .Max}} max="{{.Max}}"{{end}}
+{{- if isSet .MinLength}} minlength="{{.MinLength}}"{{end}}
+{{- if isSet .MaxLength}} maxlength="{{.MaxLength}}"{{end}}
+{{- if isSet .Step}} step="{{.Step}}"{{end}}
+{{- with .Accept}} accept="{{.}}"{{end}}
+{{- end}}
+{{- end}}
+`
+
+func htmlTitle(i Item) string {
+	if i.Label != "" {
+		return i.Label
+	}
+	return i.ID
+}
+
+func isVisible(render []string) bool {
+	return !containsString(render, RenderNone)
+}
+
+func isTranscluded(render []string) bool {
+	return containsString(render, RenderTransclude)
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func htmlPropertyValue(p Property) string {
+	v := p.Display
+	if v == "" {
+		v = htmlValue(p.Value)
+	}
+	if p.Unit != "" && v != "" {
+		v += " " + p.Unit
+	}
+	return v
+}
+
+func htmlValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := toString(v); ok {
+		return s
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(bs)
+}
+
+type htmlRef struct {
+	Label string
+	Href  string
+}
+
+func htmlItemRef(i Item) htmlRef {
+	ref := htmlRef{Label: htmlTitle(i)}
+	if ref.Label == "" {
+		ref.Label = i.Rel
+	}
+	if l, ok := i.Links.FindByRel(RelSelf); ok {
+		ref.Href = l.Href
+	} else if l, ok := i.Links.FindByRel(RelDetails); ok {
+		ref.Href = l.Href
+	}
+	return ref
+}
+
+type htmlForm struct {
+	Label       string
+	Description string
+	Action      string
+	Method      string
+	Enctype     string
+	OK          string
+	Reset       string
+	Inputs      []htmlInput
+}
+
+type htmlInput struct {
+	Parameter Parameter
+	Name      string
+	Label     string
+	Control   string
+	Value     string
+	Errors    Errors
+	Inputs    []htmlInput
+}
+
+func htmlActionForm(a Action, errs Errors) htmlForm {
+	f := htmlForm{
+		Label:       a.Label,
+		Description: a.Description,
+		Action:      a.Href,
+		Method:      "post",
+		Enctype:     ContentTypeURLEncoded,
+		OK:          a.OK,
+		Reset:       a.Reset,
+	}
+	method := strings.ToUpper(a.Method)
+	switch method {
+	case http.MethodGet:
+		f.Method = "get"
+		f.Enctype = ""
+	case "", http.MethodPost:
+	default:
+		// HTML forms can not submit other methods
+		f.Inputs = append(f.Inputs, htmlInput{Name: NameMethod, Control: TypeHidden, Value: method})
+	}
+	if f.OK == "" {
+		f.OK = a.Label
+	}
+	if f.OK == "" {
+		f.OK = "OK"
+	}
+	if strings.HasPrefix(a.Encoding, ContentTypeMultipartFormData) || hasFileParameter(a.Parameters) {
+		f.Enctype = ContentTypeMultipartFormData
+	}
+	f.Inputs = append(f.Inputs, htmlInputs(a.Parameters, "", errs)...)
+	return f
+}
+
+func hasFileParameter(ps Parameters) bool {
+	for _, p := range ps {
+		if p.Type == TypeFile {
+			return true
+		}
+		if p.Type == TypeObject && hasFileParameter(objectComponents(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func htmlInputs(ps Parameters, prefix string, errs Errors) []htmlInput {
+	var ins []htmlInput
+	for _, p := range ps {
+		in := htmlInput{
+			Parameter: p,
+			Name:      prefix + p.Name,
+			Label:     parameterLabel(p),
+			Control:   htmlControl(p),
+			Errors:    errs.FilterByName(prefix + p.Name),
+		}
+		if s, ok := toString(p.Value); ok {
+			in.Value = s
+		}
+		if p.Type == TypeObject {
+			in.Inputs = htmlInputs(objectComponents(p), in.Name+".", errs)
+		}
+		ins = append(ins, in)
+	}
+	return ins
+}
+
+func htmlControl(p Parameter) string {
+	switch p.Type {
+	case TypeSelect, TypeRadio:
+		return "select"
+	case TypeBool, TypeCheckbox:
+		return "checkbox"
+	case TypeInteger:
+		return TypeNumber
+	case TypeDatetime:
+		return "datetime-local"
+	case TypeObject:
+		return "fieldset"
+	case TypeText, "":
+		if p.Rows != nil {
+			return "textarea"
+		}
+		return TypeText
+	case TypeDatalist, "filter":
+		return TypeText
+	}
+	return p.Type
+}
+
+func htmlIsSelected(in htmlInput, o SelectOption) bool {
+	want := fmt.Sprintf("%v", o.Value)
+	if vs, ok := multipleValues(in.Parameter.Value); ok {
+		for _, v := range vs {
+			if fmt.Sprintf("%v", v) == want {
+				return true
+			}
+		}
+		return false
+	}
+	return in.Parameter.Value != nil && fmt.Sprintf("%v", in.Parameter.Value) == want
+}
+
+func htmlIsChecked(in htmlInput) bool {
+	b, _ := toBool(in.Parameter.Value)
+	return b
+}
+
+// htmlLinkForm turns a link with a query template into a GET form.
+// It returns nil if the template can not be expressed as form.
+func htmlLinkForm(l Link) *htmlForm {
+	target, names, ok := queryTemplate(l)
+	if !ok {
+		return nil
+	}
+	f := &htmlForm{
+		Label:  l.Label,
+		Method: "get",
+		OK:     l.Label,
+	}
+	if f.OK == "" {
+		f.OK = l.Rel
+	}
+	query := target.Query()
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, n := range keys {
+		for _, v := range query[n] {
+			f.Inputs = append(f.Inputs, htmlInput{Name: n, Control: TypeHidden, Value: v})
+		}
+	}
+	target.RawQuery = ""
+	f.Action = target.String()
+	for _, n := range names {
+		p, ok := l.Parameters.FindByName(n)
+		if !ok {
+			p = Parameter{Name: n, Type: TypeText}
+		}
+		f.Inputs = append(f.Inputs, htmlInputs(Parameters{p}, "", nil)...)
+	}
+	return f
+}
@@ -0,0 +1,167 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTMLHandler(t *testing.T) {
+	item := Item{
+		Label: "Orders",
+		Properties: Properties{
+			{Name: "total", Label: "Total", Value: 12.5, Unit: "EUR"},
+			{Name: "secret", Value: "hidden", Render: []string{RenderNone}},
+		},
+		Links: Links{
+			{Rel: RelSelf, Label: "Self", Href: "http://localhost/orders"},
+			{Rel: RelSearch, Label: "Search", Href: "http://localhost/orders?limit=10", Template: "{&search}"},
+		},
+		Actions: Actions{
+			{
+				Label:    "Create",
+				Rel:      "create",
+				Href:     "http://localhost/orders",
+				Method:   MethodPOST,
+				Encoding: ContentTypeJSON,
+				Parameters: Parameters{
+					ActionParameter("create"),
+					{Name: "quantity", Type: TypeInteger, Min: 0, Required: true},
+					{Name: "color", Type: TypeSelect, Options: SelectOptions{{Label: "Red", Value: "red"}}},
+				},
+			},
+		},
+		Items: Items{
+			{Label: "Order 1", Links: Links{{Rel: RelSelf, Href: "http://localhost/orders/1"}}},
+			{Label: "Order 2", Render: []string{RenderTransclude}, Properties: Properties{{Name: "state", Value: "open"}}},
+		},
+		Errors: Errors{{Name: "quantity", Message: "quantity is required"}},
+	}
+	h := HTMLHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("wrapped") != "" {
+			// other middleware may wrap the writer
+			WriteFor(&statusRecorder{ResponseWriter: w}, r, http.StatusBadRequest, item)
+			return
+		}
+		Write(w, http.StatusBadRequest, item)
+	}))
+
+	for _, target := range []string{"/orders", "/orders?wrapped=1"} {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set(HeaderAccept, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if got := rec.Header().Get(HeaderContentType); got != ContentTypeHTMLUTF8 {
+			t.Errorf("%s: want: %s, got: %s", target, ContentTypeHTMLUTF8, got)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/orders", nil)
+	req.Header.Set(HeaderAccept, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("want: %d, got: %d", http.StatusBadRequest, rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"<h1>Orders</h1>",
+		"<td>12.5 EUR</td>",
+		`<a href="http://localhost/orders" rel="self">Self</a>`,
+		`<form action="http://localhost/orders" method="get">`,
+		`<input type="hidden" name="limit" value="10">`,
+		`<input type="text" name="search">`,
+		`<form action="http://localhost/orders" method="post" enctype="application/x-www-form-urlencoded">`,
+		`<input type="hidden" name="@action" value="create">`,
+		`<input type="number" name="quantity" required min="0">`,
+		`<option value="red">Red</option>`,
+		`<div class="parameter invalid">`,
+		`<a href="http://localhost/orders/1">Order 1</a>`,
+		"<td>open</td>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing: %s\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "secret") {
+		t.Errorf("unexpected hidden property:\n%s", body)
+	}
+
+	req = httptest.NewRequest("GET", "/orders", nil)
+	req.Header.Set(HeaderAccept, ContentTypeHyperItem)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get(HeaderContentType); got != ContentTypeHyperItemUTF8 {
+		t.Errorf("want: %s, got: %s", ContentTypeHyperItemUTF8, got)
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func TestHTMLMethodOverride(t *testing.T) {
+	a := Action{
+		Href:       "http://localhost/orders/1",
+		Method:     MethodPATCH,
+		Parameters: Parameters{ActionParameter("rename"), {Name: "name", Type: TypeText}},
+	}
+	var buf strings.Builder
+	if err := RenderHTML(&buf, Item{Actions: Actions{a}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<form action="http://localhost/orders/1" method="post" enctype="application/x-www-form-urlencoded">`,
+		`<input type="hidden" name="@method" value="PATCH">`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing: %s\n%s", want, buf.String())
+		}
+	}
+
+	var method string
+	var c Command
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		c = ExtractCommand(r)
+	})
+	tests := []struct {
+		name    string
+		handler http.Handler
+		body    string
+		header  map[string]string
+		want    string
+	}{
+		{name: "override", handler: HTMLHandler(HTMLMethodOverride(next)), body: "%40method=PATCH&%40action=rename&name=x", want: http.MethodPatch},
+		{name: "unsupported method", handler: HTMLHandler(HTMLMethodOverride(next)), body: "%40method=TRACE", want: http.MethodPost},
+		{name: "not opted in", handler: HTMLHandler(next), body: "%40method=PATCH", want: http.MethodPost},
+		{name: "no html", handler: HTMLHandler(HTMLMethodOverride(next)), body: "%40method=PATCH", header: map[string]string{HeaderAccept: ContentTypeJSON}, want: http.MethodPost},
+		{name: "cross-site origin", handler: HTMLHandler(HTMLMethodOverride(next)), body: "%40method=DELETE", header: map[string]string{"Origin": "http://evil.example"}, want: http.MethodPost},
+		{name: "cross-site fetch", handler: HTMLHandler(HTMLMethodOverride(next)), body: "%40method=DELETE", header: map[string]string{"Sec-Fetch-Site": "cross-site"}, want: http.MethodPost},
+		{name: "same origin", handler: HTMLHandler(HTMLMethodOverride(next)), body: "%40method=DELETE", header: map[string]string{"Origin": "http://example.com", "Sec-Fetch-Site": "same-origin"}, want: http.MethodDelete},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/orders/1", strings.NewReader(test.body))
+			req.Header.Set(HeaderContentType, ContentTypeURLEncoded)
+			req.Header.Set(HeaderAccept, "text/html")
+			for k, v := range test.header {
+				req.Header.Set(k, v)
+			}
+			test.handler.ServeHTTP(httptest.NewRecorder(), req)
+			if method != test.want {
+				t.Errorf("want: %s, got: %s", test.want, method)
+			}
+		})
+	}
+	if c.Action != "" || c.Arguments[NameMethod] != "DELETE" {
+		t.Errorf("unexpected command: %#v", c)
+	}
+}
@@ -17,14 +17,23 @@ import (
 // HTTP headers as registered with IANA.
 // See: https://tools.ietf.org/html/rfc7231
 const (
-	HeaderContentType     = "Content-Type" // RFC 7231, 3.1.1.5
-	HeaderAccept          = "Accept"
-	HeaderAcceptLanguage  = "Accept-Language"
-	HeaderAcceptProfile   = "Accept-Profile"
-	HeaderIfNoneMatch     = "If-None-Match"
-	HeaderIfModifiedSince = "If-Modified-Since"
-	HeaderAuthorization   = "Authorization"
-	HeaderLocation        = "Location"
+	HeaderContentType       = "Content-Type" // RFC 7231, 3.1.1.5
+	HeaderAccept            = "Accept"
+	HeaderAcceptLanguage    = "Accept-Language"
+	HeaderAcceptProfile     = "Accept-Profile"
+	HeaderIfNoneMatch       = "If-None-Match"
+	HeaderIfModifiedSince   = "If-Modified-Since"
+	HeaderAuthorization     = "Authorization"
+	HeaderCookie            = "Cookie"
+	HeaderLocation          = "Location"
+	HeaderVary              = "Vary"
+	HeaderETag              = "ETag"
+	HeaderLastModified      = "Last-Modified"
+	HeaderCacheControl      = "Cache-Control"
+	HeaderExpires           = "Expires"
+	HeaderAge               = "Age"
+	HeaderIfMatch           = "If-Match"
+	HeaderIfUnmodifiedSince = "If-Unmodified-Since"
 )
 
 // HTTP content types
@@ -34,10 +43,28 @@ const (
 	ContentTypeJSON              = "application/json"                              // https://tools.ietf.org/html/rfc8259
 	ContentTypeURLEncoded        = "application/x-www-form-urlencoded"             // http://www.w3.org/TR/html
 	ContentTypeMultipartFormData = "multipart/form-data"                           // https://tools.ietf.org/html/rfc2388
+	ContentTypeHTML              = "text/html"                                     // https://html.spec.whatwg.org
+	ContentTypeHTMLUTF8          = "text/html;charset=UTF-8"                       // https://html.spec.whatwg.org
 )
 
+// WriteFor writes a hyper-item in response to the request with the given status code.
+// Within a HTMLHandler the hyper-item is rendered as HTML page if the client prefers text/html.
+// Unlike Write it does so even if other middleware wrapped the response writer.
+func WriteFor(w http.ResponseWriter, r *http.Request, status int, i Item) {
+	if IsHTMLRequest(r) {
+		WriteHTML(w, status, i)
+		return
+	}
+	Write(w, status, i)
+}
+
 // Write writes a hyper-item to the response writer with the given status code.
+// Within a HTMLHandler the hyper-item is rendered as HTML page if the client prefers text/html.
 func Write(w http.ResponseWriter, status int, i Item) {
+	if isHTMLWriter(w) {
+		WriteHTML(w, status, i)
+		return
+	}
 	w.Header().Set(HeaderContentType, ContentTypeHyperItemUTF8)
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(i)
@@ -109,6 +136,17 @@ func ExtractCommand(r *http.Request) Command {
 			}
 		}
 		return c
+	case strings.HasPrefix(ct, ContentTypeCollectionJSON):
+		as, err := decodeCollectionJSONTemplate(r.Body)
+		if err != nil {
+			return c
+		}
+		c.Arguments = as
+		if p := c.Arguments.String(NameAction); p != "" {
+			delete(c.Arguments, NameAction)
+			c.Action = p
+		}
+		return c
 	default:
 		err := json.NewDecoder(r.Body).Decode(&c.Arguments)
 		if err != nil {
@@ -0,0 +1,211 @@
+package hyper
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Pagination styles used by the ItemIterator when a page has no next link.
+const (
+	PaginationOffset = "offset"
+	PaginationAfter  = "after"
+	PaginationBefore = "before"
+)
+
+// IterateOption configures an ItemIterator.
+type IterateOption func(*ItemIterator)
+
+// MaxPages stops the iteration after n pages.
+func MaxPages(n int) IterateOption {
+	return func(it *ItemIterator) {
+		it.maxPages = n
+	}
+}
+
+// MaxItems stops the iteration after n items.
+func MaxItems(n int) IterateOption {
+	return func(it *ItemIterator) {
+		it.maxItems = n
+	}
+}
+
+// Paginate lets the iterator derive the next page if a page has no next link.
+// With PaginationOffset the skip query parameter is advanced by limit, with PaginationAfter
+// the after query parameter is set to the ID of the last item of the page and with PaginationBefore
+// the before query parameter is set to the ID of the first item of the page (paging backwards).
+// All of them only continue after full pages of limit items.
+func Paginate(style string, limit uint64) IterateOption {
+	return func(it *ItemIterator) {
+		it.style = style
+		it.limit = limit
+	}
+}
+
+// IterateRequest applies the request options to every page request.
+func IterateRequest(opts ...func(*http.Request)) IterateOption {
+	return func(it *ItemIterator) {
+		it.reqOpts = append(it.reqOpts, opts...)
+	}
+}
+
+// Iterate returns an iterator over the sub-Items of the collection at url and all its
+// following pages, which are found by their next link (RelNext). Empty pages with a next
+// link are skipped; the iteration stops at a page without next link or after MaxPages.
+//
+//	it := c.Iterate(url, MaxItems(100))
+//	for it.Next() {
+//		item := it.Item()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+func (c *Client) Iterate(url string, opts ...IterateOption) *ItemIterator {
+	return c.IterateContext(context.Background(), url, opts...)
+}
+
+// IterateContext is like Iterate with a context.
+func (c *Client) IterateContext(ctx context.Context, url string, opts ...IterateOption) *ItemIterator {
+	it := &ItemIterator{
+		client:  c,
+		ctx:     ctx,
+		next:    url,
+		visited: map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(it)
+	}
+	if it.limit > 0 {
+		if u, err := setQuery(url, "limit", strconv.FormatUint(it.limit, 10)); err == nil {
+			it.next = u
+		}
+	}
+	return it
+}
+
+// ItemIterator iterates over the sub-Items of a paginated collection.
+type ItemIterator struct {
+	client   *Client
+	ctx      context.Context
+	reqOpts  []func(*http.Request)
+	maxPages int
+	maxItems int
+	style    string
+	limit    uint64
+
+	next    string
+	nextErr error
+	visited map[string]bool
+	page    Item
+	index   int
+	pages   int
+	items   int
+	item    Item
+	err     error
+}
+
+// Next advances to the next Item, fetching the next page when needed.
+// It returns false when the iteration stops, either after the last Item or on an error.
+func (it *ItemIterator) Next() bool {
+	if it.err != nil || (it.maxItems > 0 && it.items >= it.maxItems) {
+		return false
+	}
+	for it.index >= len(it.page.Items) {
+		if !it.fetch() {
+			return false
+		}
+	}
+	it.item = it.page.Items[it.index]
+	it.index++
+	it.items++
+	return true
+}
+
+// Item returns the current Item.
+func (it *ItemIterator) Item() Item {
+	return it.item
+}
+
+// Page returns the current page.
+func (it *ItemIterator) Page() Item {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ItemIterator) Err() error {
+	return it.err
+}
+
+// fetch fetches the next page. It reports false if there is none, the page may be empty.
+func (it *ItemIterator) fetch() bool {
+	if it.nextErr != nil {
+		it.err = it.nextErr
+		return false
+	}
+	if it.next == "" || it.visited[it.next] || (it.maxPages > 0 && it.pages >= it.maxPages) {
+		return false
+	}
+	current := it.next
+	it.visited[current] = true
+	page, err := it.client.FetchContext(it.ctx, current, it.reqOpts...)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page, it.index = page, 0
+	it.pages++
+	it.next, it.nextErr = it.nextURL(current, page)
+	return true
+}
+
+// nextURL determines the URL of the page following page.
+func (it *ItemIterator) nextURL(current string, page Item) (string, error) {
+	base := current
+	if self, ok := page.Links.FindByRel(RelSelf); ok && self.Href != "" {
+		if s, err := resolveHref(current, self.Href); err == nil {
+			base = s
+		}
+	}
+	if l, ok := page.Links.FindByRel(RelNext); ok {
+		href, err := expandLink(l, nil)
+		if err != nil {
+			return "", err
+		}
+		return resolveHref(base, href)
+	}
+	if it.limit == 0 || uint64(len(page.Items)) < it.limit {
+		return "", nil
+	}
+	switch it.style {
+	case PaginationOffset:
+		u, err := url.Parse(current)
+		if err != nil {
+			return "", err
+		}
+		skip, _ := strconv.ParseUint(u.Query().Get("skip"), 10, 64)
+		return setQuery(current, "skip", strconv.FormatUint(skip+it.limit, 10))
+	case PaginationAfter:
+		if last := page.Items[len(page.Items)-1]; last.ID != "" {
+			return setQuery(current, "after", last.ID)
+		}
+	case PaginationBefore:
+		if first := page.Items[0]; first.ID != "" {
+			return setQuery(current, "before", first.ID)
+		}
+	}
+	return "", nil
+}
+
+// setQuery sets a query parameter of a URL.
+func setQuery(href string, key string, value string) (string, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,143 @@
+package hyper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestClientIterate(t *testing.T) {
+	const total = 7
+	var requests int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		skip, _ := strconv.Atoi(q.Get("skip"))
+		if after := q.Get("after"); after != "" {
+			skip, _ = strconv.Atoi(after)
+		}
+		if before := q.Get("before"); before != "" {
+			b, _ := strconv.Atoi(before)
+			skip = b - 1 - limit
+		}
+		page := Item{}
+		for i := skip + 1; i <= skip+limit && i <= total; i++ {
+			if i >= 1 {
+				page.AddItem(Item{ID: strconv.Itoa(i)})
+			}
+		}
+		if r.URL.Path == "/linked" && skip+limit < total {
+			page.AddLink(Link{Rel: RelNext, Href: fmt.Sprintf("linked?skip=%d&limit=%d", skip+limit, limit)})
+		}
+		Write(w, http.StatusOK, page)
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name     string
+		url      string
+		opts     []IterateOption
+		want     []string
+		requests int
+	}{
+		{name: "next links", url: s.URL + "/linked?limit=3", want: []string{"1", "2", "3", "4", "5", "6", "7"}, requests: 3},
+		{name: "offset", url: s.URL + "/offset", opts: []IterateOption{Paginate(PaginationOffset, 3)}, want: []string{"1", "2", "3", "4", "5", "6", "7"}, requests: 3},
+		{name: "after", url: s.URL + "/cursor", opts: []IterateOption{Paginate(PaginationAfter, 2)}, want: []string{"1", "2", "3", "4", "5", "6", "7"}, requests: 4},
+		{name: "before", url: s.URL + "/cursor?before=8", opts: []IterateOption{Paginate(PaginationBefore, 3)}, want: []string{"5", "6", "7", "2", "3", "4", "1"}, requests: 3},
+		{name: "max items", url: s.URL + "/linked?limit=3", opts: []IterateOption{MaxItems(4)}, want: []string{"1", "2", "3", "4"}, requests: 2},
+		{name: "max pages", url: s.URL + "/linked?limit=3", opts: []IterateOption{MaxPages(1)}, want: []string{"1", "2", "3"}, requests: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = 0
+			it := NewClient().Iterate(test.url, test.opts...)
+			var got []string
+			for it.Next() {
+				got = append(got, it.Item().ID)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.want, got) {
+				t.Errorf("want: %v, got: %v", test.want, got)
+			}
+			if requests != test.requests {
+				t.Errorf("want: %d requests, got: %d", test.requests, requests)
+			}
+		})
+	}
+}
+
+func TestClientIterateEmptyPage(t *testing.T) {
+	pages := map[string]Item{
+		"/1": {Items: Items{{ID: "1"}}, Links: Links{{Rel: RelNext, Href: "/2"}}},
+		"/2": {Links: Links{{Rel: RelNext, Href: "/3"}}},
+		"/3": {Items: Items{{ID: "3"}}, Links: Links{{Rel: RelNext, Href: "/4"}}},
+		"/4": {},
+	}
+	var requests int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		Write(w, http.StatusOK, pages[r.URL.Path])
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name     string
+		opts     []IterateOption
+		want     []string
+		requests int
+	}{
+		{name: "all", want: []string{"1", "3"}, requests: 4},
+		{name: "max pages", opts: []IterateOption{MaxPages(2)}, want: []string{"1"}, requests: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = 0
+			it := NewClient().Iterate(s.URL+"/1", test.opts...)
+			var got []string
+			for it.Next() {
+				got = append(got, it.Item().ID)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.want, got) {
+				t.Errorf("want: %v, got: %v", test.want, got)
+			}
+			if requests != test.requests {
+				t.Errorf("want: %d requests, got: %d", test.requests, requests)
+			}
+		})
+	}
+}
+
+func TestClientIterateError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("skip") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		Write(w, http.StatusOK, Item{
+			Items: Items{{ID: "1"}},
+			Links: Links{{Rel: RelNext, Href: "?skip=1"}},
+		})
+	}))
+	defer s.Close()
+
+	it := NewClient().Iterate(s.URL)
+	var n int
+	for it.Next() {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("want: %d, got: %d", 1, n)
+	}
+	if !IsServerError(it.Err()) {
+		t.Errorf("want: server error, got: %v", it.Err())
+	}
+}
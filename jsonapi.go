@@ -0,0 +1,429 @@
+package hyper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentTypeJSONAPI is the media type of JSON:API.
+// See: https://jsonapi.org/format/
+const ContentTypeJSONAPI = "application/vnd.api+json"
+
+// JSONAPIDocument is a JSON:API top-level document. Data is either a
+// JSONAPIResource or a slice of them.
+type JSONAPIDocument struct {
+	Data     interface{}       `json:"data,omitempty"`
+	Included []JSONAPIResource `json:"included,omitempty"`
+	Links    map[string]string `json:"links,omitempty"`
+	Errors   []JSONAPIError    `json:"errors,omitempty"`
+}
+
+// JSONAPIResource is a JSON:API resource object.
+type JSONAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id,omitempty"`
+	Attributes    map[string]interface{}         `json:"attributes,omitempty"`
+	Relationships map[string]JSONAPIRelationship `json:"relationships,omitempty"`
+	Links         map[string]string              `json:"links,omitempty"`
+}
+
+// JSONAPIRelationship is a JSON:API relationship object. Data is either a
+// JSONAPIResourceIdentifier or a slice of them.
+type JSONAPIRelationship struct {
+	Data  interface{}       `json:"data,omitempty"`
+	Links map[string]string `json:"links,omitempty"`
+}
+
+// JSONAPIResourceIdentifier identifies a JSON:API resource.
+type JSONAPIResourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// JSONAPIError is a JSON:API error object.
+type JSONAPIError struct {
+	Code   string              `json:"code,omitempty"`
+	Title  string              `json:"title,omitempty"`
+	Detail string              `json:"detail,omitempty"`
+	Source *JSONAPIErrorSource `json:"source,omitempty"`
+}
+
+// JSONAPIErrorSource references the source of a JSON:API error.
+type JSONAPIErrorSource struct {
+	Parameter string `json:"parameter,omitempty"`
+}
+
+const jsonAPIRelPrevious = "prev"
+
+var jsonAPIDocumentRels = []string{RelSelf, RelFirst, RelPrevious, RelNext, RelLast}
+
+// ItemToJSONAPI converts an Item into a JSON:API document.
+//
+// An Item with Type or ID becomes the primary resource; an Item without both is treated
+// as collection and its sub-Items become the primary data. Properties and the fields of
+// a JSON object in Data become attributes. Sub-Items of a resource become relationships
+// named by their Rel, with the full sub-Items listed as included unless they are primary resources.
+// Sub-Items without Type or ID can not be identified and are dropped. Links with a rel other
+// than self, first, previous, next and last become relationships with a related link.
+// If the Item carries Errors only the errors are emitted, as JSON:API forbids data and
+// errors in the same document. Actions have no JSON:API counterpart and are dropped.
+func ItemToJSONAPI(i Item) JSONAPIDocument {
+	d := JSONAPIDocument{}
+	if len(i.Errors) > 0 {
+		for _, e := range i.Errors {
+			je := JSONAPIError{Code: e.Code, Title: e.Label, Detail: e.Message}
+			if e.Name != "" {
+				je.Source = &JSONAPIErrorSource{Parameter: e.Name}
+			}
+			d.Errors = append(d.Errors, je)
+		}
+		return d
+	}
+	// primary resources must not be repeated in included
+	included := map[JSONAPIResourceIdentifier]bool{}
+	if i.Type == "" && i.ID == "" {
+		var subs Items
+		for _, sub := range i.Items {
+			if sub.Type == "" || sub.ID == "" {
+				continue
+			}
+			subs = append(subs, sub)
+			included[JSONAPIResourceIdentifier{Type: sub.Type, ID: sub.ID}] = true
+		}
+		rs := []JSONAPIResource{}
+		for _, sub := range subs {
+			rs = append(rs, itemToJSONAPIResource(sub, &d, included))
+		}
+		d.Data = rs
+	} else {
+		included[JSONAPIResourceIdentifier{Type: i.Type, ID: i.ID}] = true
+		d.Data = itemToJSONAPIResource(i, &d, included)
+	}
+	for _, rel := range jsonAPIDocumentRels {
+		if l, ok := i.Links.FindByRel(rel); ok {
+			if d.Links == nil {
+				d.Links = map[string]string{}
+			}
+			d.Links[jsonAPIRel(rel)] = l.Href
+		}
+	}
+	return d
+}
+
+func itemToJSONAPIResource(i Item, d *JSONAPIDocument, included map[JSONAPIResourceIdentifier]bool) JSONAPIResource {
+	r := JSONAPIResource{
+		Type: i.Type,
+		ID:   i.ID,
+	}
+	attrs := map[string]interface{}{}
+	if len(i.Data) > 0 {
+		json.Unmarshal(i.Data, &attrs)
+	}
+	for _, p := range i.Properties {
+		attrs[p.Name] = p.Value
+	}
+	if len(attrs) > 0 {
+		r.Attributes = attrs
+	}
+	if l, ok := i.Links.FindByRel(RelSelf); ok {
+		r.Links = map[string]string{RelSelf: l.Href}
+	}
+
+	ids := map[string][]JSONAPIResourceIdentifier{}
+	var rels []string
+	for _, sub := range i.Items {
+		if sub.Type == "" || sub.ID == "" {
+			continue
+		}
+		rel := sub.Rel
+		if rel == "" {
+			rel = RelItem
+		}
+		if _, ok := ids[rel]; !ok {
+			rels = append(rels, rel)
+		}
+		id := JSONAPIResourceIdentifier{Type: sub.Type, ID: sub.ID}
+		ids[rel] = append(ids[rel], id)
+		if !included[id] {
+			included[id] = true
+			d.Included = append(d.Included, itemToJSONAPIResource(sub, d, included))
+		}
+	}
+	for _, rel := range rels {
+		if r.Relationships == nil {
+			r.Relationships = map[string]JSONAPIRelationship{}
+		}
+		if is := ids[rel]; len(is) == 1 {
+			r.Relationships[rel] = JSONAPIRelationship{Data: is[0]}
+		} else {
+			r.Relationships[rel] = JSONAPIRelationship{Data: is}
+		}
+	}
+	for _, l := range i.Links {
+		if containsString(jsonAPIDocumentRels, l.Rel) || l.Href == "" {
+			continue
+		}
+		if r.Relationships == nil {
+			r.Relationships = map[string]JSONAPIRelationship{}
+		}
+		rel := r.Relationships[l.Rel]
+		rel.Links = map[string]string{"related": l.Href}
+		r.Relationships[l.Rel] = rel
+	}
+	return r
+}
+
+// JSONAPIToItem converts a JSON:API document into an Item.
+// A single primary resource becomes the Item itself, a collection of primary
+// resources becomes the sub-Items. Relationships become sub-Items named by their
+// relationship (filled from included where available) and related links.
+func JSONAPIToItem(d JSONAPIDocument) (Item, error) {
+	i := Item{}
+	for _, e := range d.Errors {
+		err := Error{Code: e.Code, Label: e.Title, Message: e.Detail}
+		if e.Source != nil {
+			err.Name = e.Source.Parameter
+		}
+		i.Errors = append(i.Errors, err)
+	}
+	included := map[JSONAPIResourceIdentifier]JSONAPIResource{}
+	for _, r := range d.Included {
+		included[JSONAPIResourceIdentifier{Type: r.Type, ID: r.ID}] = r
+	}
+	if d.Data != nil {
+		bs, err := json.Marshal(d.Data)
+		if err != nil {
+			return Item{}, err
+		}
+		bs = bytes.TrimSpace(bs)
+		if len(bs) > 0 && bs[0] == '[' {
+			rs := []JSONAPIResource{}
+			if err := json.Unmarshal(bs, &rs); err != nil {
+				return Item{}, fmt.Errorf("jsonapi: invalid data: %v", err)
+			}
+			for _, r := range rs {
+				sub, err := jsonAPIResourceToItem(r, included, 0)
+				if err != nil {
+					return Item{}, err
+				}
+				i.AddItem(sub)
+			}
+		} else if string(bs) != "null" {
+			r := JSONAPIResource{}
+			if err := json.Unmarshal(bs, &r); err != nil {
+				return Item{}, fmt.Errorf("jsonapi: invalid data: %v", err)
+			}
+			ri, err := jsonAPIResourceToItem(r, included, 0)
+			if err != nil {
+				return Item{}, err
+			}
+			ri.Errors = i.Errors
+			i = ri
+		}
+	}
+	for _, rel := range jsonAPIDocumentRels {
+		href, ok := d.Links[jsonAPIRel(rel)]
+		if !ok {
+			continue
+		}
+		if _, exists := i.Links.FindByRel(rel); !exists {
+			i.AddLink(Link{Rel: rel, Href: href})
+		}
+	}
+	return i, nil
+}
+
+// jsonAPIMaxDepth limits the resolution of relationships through included resources.
+const jsonAPIMaxDepth = 8
+
+func jsonAPIResourceToItem(r JSONAPIResource, included map[JSONAPIResourceIdentifier]JSONAPIResource, depth int) (Item, error) {
+	i := Item{
+		Type: r.Type,
+		ID:   r.ID,
+	}
+	for _, n := range sortedKeys(r.Attributes) {
+		i.AddProperty(Property{Name: n, Value: r.Attributes[n]})
+	}
+	if href, ok := r.Links[RelSelf]; ok {
+		i.AddLink(Link{Rel: RelSelf, Href: href})
+	}
+	var rels []string
+	for rel := range r.Relationships {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	for _, rel := range rels {
+		relationship := r.Relationships[rel]
+		if href, ok := relationship.Links["related"]; ok {
+			i.AddLink(Link{Rel: rel, Href: href})
+		}
+		if relationship.Data == nil {
+			continue
+		}
+		bs, err := json.Marshal(relationship.Data)
+		if err != nil {
+			return Item{}, err
+		}
+		var ids []JSONAPIResourceIdentifier
+		if bs = bytes.TrimSpace(bs); len(bs) > 0 && bs[0] == '[' {
+			err = json.Unmarshal(bs, &ids)
+		} else if string(bs) != "null" {
+			id := JSONAPIResourceIdentifier{}
+			err = json.Unmarshal(bs, &id)
+			ids = append(ids, id)
+		}
+		if err != nil {
+			return Item{}, fmt.Errorf("jsonapi: invalid relationship %s: %v", rel, err)
+		}
+		for _, id := range ids {
+			sub := Item{Type: id.Type, ID: id.ID}
+			if inc, ok := included[id]; ok && depth < jsonAPIMaxDepth {
+				if sub, err = jsonAPIResourceToItem(inc, included, depth+1); err != nil {
+					return Item{}, err
+				}
+			}
+			if rel != RelItem {
+				sub.Rel = rel
+			}
+			i.AddItem(sub)
+		}
+	}
+	return i, nil
+}
+
+func jsonAPIRel(rel string) string {
+	if rel == RelPrevious {
+		return jsonAPIRelPrevious
+	}
+	return rel
+}
+
+// EncodeJSONAPI encodes an Item as JSON:API document.
+func EncodeJSONAPI(w io.Writer, i Item) error {
+	return json.NewEncoder(w).Encode(ItemToJSONAPI(i))
+}
+
+// DecodeJSONAPI decodes a JSON:API document into an Item.
+func DecodeJSONAPI(r io.Reader) (Item, error) {
+	d := JSONAPIDocument{}
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return Item{}, err
+	}
+	return JSONAPIToItem(d)
+}
+
+// ParseJSONAPIMeta parses the JSON:API query parameters filter, sort and page into Meta.
+//
+//	filter[name]=value          equals (a comma separated list means in)
+//	filter[name][op]=value      any FilterOperator, e.g. filter[age][geq]=18 or filter[age][bet]=18,65
+//	sort=-created,name          descending created, ascending name
+//	page[offset]=20&page[limit]=10
+//	page[number]=3&page[size]=10 (page[number] requires page[size])
+//	page[after]=abc / page[before]=abc
+//
+// The search parameter is taken over as is.
+func ParseJSONAPIMeta(u *url.URL) (Meta, error) {
+	q := u.Query()
+	m := Meta{
+		Search: q.Get("search"),
+	}
+	var keys []string
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !strings.HasPrefix(k, "filter[") {
+			continue
+		}
+		path := strings.Split(strings.TrimSuffix(strings.TrimPrefix(k, "filter["), "]"), "][")
+		name := path[0]
+		op := FilterOperatorEquals
+		if len(path) > 1 {
+			op = FilterOperator(path[1])
+		}
+		for _, v := range q[k] {
+			fc, err := jsonAPIFilterComponent(name, op, v)
+			if err != nil {
+				return Meta{}, fmt.Errorf("parse filter: %s", err)
+			}
+			m.Filter = append(m.Filter, fc)
+		}
+	}
+	if s := q.Get("sort"); s != "" {
+		for _, n := range strings.Split(s, ",") {
+			sc := SortComponent{Name: n, Order: SortOrderAscending}
+			if strings.HasPrefix(n, "-") {
+				sc = SortComponent{Name: n[1:], Order: SortOrderDescending}
+			}
+			if sc.Name == "" {
+				return Meta{}, fmt.Errorf("parse sort: empty field name")
+			}
+			m.Sort = append(m.Sort, sc)
+		}
+	}
+	var err error
+	if m.Limit, err = jsonAPIPageValue(q, "limit"); err != nil {
+		return Meta{}, err
+	}
+	if m.Skip, err = jsonAPIPageValue(q, "offset"); err != nil {
+		return Meta{}, err
+	}
+	size, err := jsonAPIPageValue(q, "size")
+	if err != nil {
+		return Meta{}, err
+	}
+	number, err := jsonAPIPageValue(q, "number")
+	if err != nil {
+		return Meta{}, err
+	}
+	if _, ok := q["page[number]"]; ok && size == 0 {
+		return Meta{}, fmt.Errorf("parse page[number]: requires page[size]")
+	}
+	if size > 0 {
+		m.Limit = size
+		if number > 1 {
+			m.Skip = (number - 1) * size
+		}
+	}
+	m.After = q.Get("page[after]")
+	m.Before = q.Get("page[before]")
+	return m, nil
+}
+
+func jsonAPIFilterComponent(name string, op FilterOperator, v string) (FilterComponent, error) {
+	values := strings.Split(v, ",")
+	switch op {
+	case FilterOperatorEquals:
+		if len(values) > 1 {
+			return FilterComponent{Name: name, Operator: FilterOperatorIn, Value: values}, nil
+		}
+	case FilterOperatorIn, FilterOperatorNotIn:
+		return FilterComponent{Name: name, Operator: op, Value: values}, nil
+	case FilterOperatorBetween, FilterOperatorNotBetween:
+		if len(values) < 2 {
+			return FilterComponent{}, fmt.Errorf("invalid size of filter-between values (must >= 2): %d", len(values))
+		}
+		return FilterComponent{Name: name, Operator: op, Value: values[:2]}, nil
+	}
+	return FilterComponent{Name: name, Operator: op, Value: v}, nil
+}
+
+func jsonAPIPageValue(q url.Values, name string) (uint64, error) {
+	key := "page[" + name + "]"
+	v, ok := q[key]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(v[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %s", key, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,193 @@
+package hyper
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestItemToJSONAPI(t *testing.T) {
+	i := Item{
+		Type:       "articles",
+		ID:         "1",
+		Properties: Properties{{Name: "title", Value: "JSON:API"}},
+		Links: Links{
+			{Rel: RelSelf, Href: "/articles/1"},
+			{Rel: "comments", Href: "/articles/1/comments"},
+		},
+		Items: Items{
+			{Rel: "author", Type: "people", ID: "9", Properties: Properties{{Name: "name", Value: "Dan"}}},
+			{Rel: "note", Properties: Properties{{Name: "text", Value: "untyped"}}},
+			{Rel: "translation", Type: "articles", ID: "1"},
+		},
+	}
+	bs, err := json.Marshal(ItemToJSONAPI(i))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	json.Unmarshal(bs, &got)
+	want := map[string]interface{}{}
+	json.Unmarshal([]byte(`{
+		"data": {
+			"type": "articles",
+			"id": "1",
+			"attributes": {"title": "JSON:API"},
+			"relationships": {
+				"author": {"data": {"type": "people", "id": "9"}},
+				"translation": {"data": {"type": "articles", "id": "1"}},
+				"comments": {"links": {"related": "/articles/1/comments"}}
+			},
+			"links": {"self": "/articles/1"}
+		},
+		"included": [{"type": "people", "id": "9", "attributes": {"name": "Dan"}}],
+		"links": {"self": "/articles/1"}
+	}`), &want)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+func TestItemToJSONAPICollection(t *testing.T) {
+	i := Item{
+		Items: Items{
+			{Type: "articles", ID: "1"},
+			{Type: "articles", Properties: Properties{{Name: "title", Value: "no id"}}},
+			{Rel: "note", Properties: Properties{{Name: "text", Value: "untyped"}}},
+		},
+	}
+	bs, err := json.Marshal(ItemToJSONAPI(i))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"data":[{"type":"articles","id":"1"}]}`
+	if got := string(bs); want != got {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestItemToJSONAPIErrors(t *testing.T) {
+	i := Item{
+		Type:   "articles",
+		Errors: Errors{{Label: "Title", Message: "is required", Code: ErrorCodeRequired, Name: "title"}},
+	}
+	want := JSONAPIDocument{
+		Errors: []JSONAPIError{{Code: ErrorCodeRequired, Title: "Title", Detail: "is required", Source: &JSONAPIErrorSource{Parameter: "title"}}},
+	}
+	if got := ItemToJSONAPI(i); !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant: %#v\ngot:  %#v", want, got)
+	}
+}
+
+func TestJSONAPIRoundTrip(t *testing.T) {
+	want := Item{
+		Links: Links{
+			{Rel: RelNext, Href: "/articles?page[offset]=2"},
+			{Rel: RelPrevious, Href: "/articles?page[offset]=0"},
+		},
+		Items: Items{
+			{
+				Type:       "articles",
+				ID:         "1",
+				Properties: Properties{{Name: "title", Value: "One"}},
+				Items:      Items{{Rel: "author", Type: "people", ID: "9", Properties: Properties{{Name: "name", Value: "Dan"}}}},
+			},
+			{
+				Type:       "articles",
+				ID:         "2",
+				Properties: Properties{{Name: "title", Value: "Two"}},
+				Items:      Items{{Rel: "author", Type: "people", ID: "9", Properties: Properties{{Name: "name", Value: "Dan"}}}},
+			},
+		},
+	}
+	buf := &bytes.Buffer{}
+	if err := EncodeJSONAPI(buf, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeJSONAPI(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// document links are restored in a fixed order
+	want.Links = Links{want.Links[1], want.Links[0]}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant: %#v\ngot:  %#v", want, got)
+	}
+}
+
+func TestParseJSONAPIMeta(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Meta
+		err  bool
+	}{
+		{
+			in:   "/articles",
+			want: Meta{},
+		},
+		{
+			in: "/articles?filter[author]=9&filter[tag]=go,api&filter[age][bet]=18,65&sort=-created,title&page[offset]=20&page[limit]=10",
+			want: Meta{
+				Filter: Filter{
+					{Name: "age", Operator: FilterOperatorBetween, Value: []string{"18", "65"}},
+					{Name: "author", Operator: FilterOperatorEquals, Value: "9"},
+					{Name: "tag", Operator: FilterOperatorIn, Value: []string{"go", "api"}},
+				},
+				Sort: Sort{
+					{Name: "created", Order: SortOrderDescending},
+					{Name: "title", Order: SortOrderAscending},
+				},
+				Skip:  20,
+				Limit: 10,
+			},
+		},
+		{
+			in:   "/articles?page[number]=3&page[size]=10&search=go",
+			want: Meta{Search: "go", Skip: 20, Limit: 10},
+		},
+		{
+			in:   "/articles?page[after]=abc",
+			want: Meta{After: "abc"},
+		},
+		{
+			in:  "/articles?page[limit]=ten",
+			err: true,
+		},
+		{
+			in:  "/articles?filter[age][bet]=18",
+			err: true,
+		},
+		{
+			in:  "/articles?page[number]=3",
+			err: true,
+		},
+		{
+			in:  "/articles?sort=,",
+			err: true,
+		},
+		{
+			in:  "/articles?sort=-",
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			u, _ := url.Parse(test.in)
+			got, err := ParseJSONAPIMeta(u)
+			if test.err {
+				if err == nil {
+					t.Errorf("want: error, got: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.want, got) {
+				t.Errorf("\nwant: %#v\ngot:  %#v", test.want, got)
+			}
+		})
+	}
+}
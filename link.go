@@ -1,5 +1,15 @@
 package hyper
 
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/cognicraft/uri"
+)
+
 // Link .
 type Link struct {
 	Label          string     `json:"label,omitempty"`
@@ -65,4 +75,127 @@ const (
 	RelSelf     = "self"
 	RelDetails  = "details"
 	RelSearch   = "search"
+	RelItem     = "item"
 )
+
+// templateHref combines the Href and the Template of a Link into a single URI template.
+func templateHref(l Link) string {
+	switch {
+	case l.Template == "":
+		return l.Href
+	case strings.HasPrefix(l.Template, "{?") && strings.Contains(l.Href, "?"):
+		// the href already has a query
+		return l.Href + "{&" + l.Template[2:]
+	case strings.HasPrefix(l.Template, "{"):
+		return l.Href + l.Template
+	case strings.HasPrefix(l.Template, "?"):
+		href := l.Href
+		if i := strings.Index(href, "?"); i >= 0 {
+			href = href[:i]
+		}
+		return href + l.Template
+	}
+	return l.Template
+}
+
+var templateVariablePattern = regexp.MustCompile(`\{([?&/;.#+]?)([^}]*)\}`)
+
+// queryTemplate resolves a Link whose Template only adds query parameters into the target URL
+// (including any fixed query parameters) and the names of the template variables.
+// It reports false for all other templates.
+func queryTemplate(l Link) (*url.URL, []string, bool) {
+	i := strings.Index(l.Template, "{")
+	if i < 0 {
+		return nil, nil, false
+	}
+	literal, vars := l.Template[:i], l.Template[i:]
+	var names []string
+	for _, m := range templateVariablePattern.FindAllStringSubmatch(vars, -1) {
+		if m[1] != "?" && m[1] != "&" {
+			return nil, nil, false
+		}
+		for _, n := range strings.Split(m[2], ",") {
+			n = strings.TrimSuffix(n, "*")
+			if j := strings.Index(n, ":"); j >= 0 {
+				n = n[:j]
+			}
+			names = append(names, n)
+		}
+	}
+	base, err := url.Parse(l.Href)
+	if err != nil {
+		return nil, nil, false
+	}
+	target, err := base.Parse(literal)
+	if err != nil {
+		return nil, nil, false
+	}
+	return target, names, true
+}
+
+// expandLink expands the URI template of a Link (see templateHref) with vars.
+// Parameters of the Link with a Value serve as defaults for missing vars.
+// Slices and maps with string keys are expanded as lists and associative arrays.
+func expandLink(l Link, vars map[string]interface{}) (string, error) {
+	if l.Template == "" {
+		return l.Href, nil
+	}
+	t, err := uri.Parse(templateHref(l))
+	if err != nil {
+		return "", fmt.Errorf("parse template: %v", err)
+	}
+	vs := map[string]interface{}{}
+	for _, p := range l.Parameters {
+		if v, ok := templateValue(p.Value); ok {
+			vs[p.Name] = v
+		}
+	}
+	for k, v := range vars {
+		if v, ok := templateValue(v); ok {
+			vs[k] = v
+		} else {
+			delete(vs, k)
+		}
+	}
+	href, err := t.Expand(vs)
+	if err != nil {
+		return "", fmt.Errorf("expand template: %v", err)
+	}
+	return href, nil
+}
+
+// templateValue converts a value into a form understood by the uri package.
+func templateValue(v interface{}) (interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, false
+		}
+		return templateValue(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes()), true
+		}
+		vs := []interface{}{}
+		for i := 0; i < rv.Len(); i++ {
+			vs = append(vs, rv.Index(i).Interface())
+		}
+		return vs, true
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		vs := map[string]interface{}{}
+		for _, k := range rv.MapKeys() {
+			vs[k.String()] = rv.MapIndex(k).Interface()
+		}
+		return vs, true
+	case reflect.String:
+		return rv.String(), true
+	}
+	return v, true
+}
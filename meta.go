@@ -6,11 +6,21 @@ import (
 	"log"
 	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/cognicraft/uri"
 )
 
+// Meta describes the filtering, searching, sorting and paging of a collection.
+//
+// The Evaluator (in memory) and the SQLBuilder (in SQL) apply a Meta by the same rules:
+//   - An element lacking the field of a filter component (missing, nil or NULL) matches neither
+//     its operator nor the negated operator (neq, nin, nlike and nbet); a not group around the
+//     component however matches it.
+//   - Empty groups (and, or and not groups without components other than empty groups) are
+//     ignored, so Query can leave them out.
+//   - Integers are compared as int64, other numbers as float64.
+//   - Elements lacking the field of a sort component come first, in ascending and descending order.
+//   - Skip is ignored if After or Before is set, as the cursor replaces the offset.
 type Meta struct {
 	Filter Filter `json:"filter,omitemty"`
 	Sort   Sort   `json:"sort,omitempty"`
@@ -118,8 +128,8 @@ func (m Meta) Query() string {
 	}
 
 	vs := map[string]interface{}{}
-	if !m.Filter.IsZero() {
-		vs["filter"] = m.currentFilter()
+	if fcs := m.currentFilter(); len(fcs) > 0 {
+		vs["filter"] = fcs
 	}
 	if !m.Sort.IsZero() {
 		vs["sort"] = m.currentSort()
@@ -173,7 +183,7 @@ func (m Meta) FilterTemplate() string {
 }
 
 func (m Meta) SearchTemplate() string {
-	if m.Filter.IsZero() && m.Sort.IsZero() && m.Limit == 0 {
+	if len(m.currentFilter()) == 0 && m.Sort.IsZero() && m.Limit == 0 {
 		return "{?search}"
 	}
 	qt, err := uri.Parse("{?filter*,sort*,limit}")
@@ -184,8 +194,8 @@ func (m Meta) SearchTemplate() string {
 	if !m.Sort.IsZero() {
 		vs["sort"] = m.currentSort()
 	}
-	if !m.Filter.IsZero() {
-		vs["filter"] = m.currentFilter()
+	if fcs := m.currentFilter(); len(fcs) > 0 {
+		vs["filter"] = fcs
 	}
 	if m.Limit > 0 {
 		vs["limit"] = m.Limit
@@ -198,7 +208,7 @@ func (m Meta) SearchTemplate() string {
 }
 
 func (m Meta) SortTemplate() string {
-	if m.Filter.IsZero() && m.Search == "" && m.Limit == 0 {
+	if len(m.currentFilter()) == 0 && m.Search == "" && m.Limit == 0 {
 		return "{?sort*}"
 	}
 	qt, err := uri.Parse("{?filter*,search,limit}")
@@ -206,8 +216,8 @@ func (m Meta) SortTemplate() string {
 		log.Printf("uri parse: %s", err)
 	}
 	vs := map[string]interface{}{}
-	if !m.Filter.IsZero() {
-		vs["filter"] = m.currentFilter()
+	if fcs := m.currentFilter(); len(fcs) > 0 {
+		vs["filter"] = fcs
 	}
 	if m.Search != "" {
 		vs["search"] = m.Search
@@ -225,17 +235,8 @@ func (m Meta) SortTemplate() string {
 func (m Meta) currentFilter() []interface{} {
 	fcs := []interface{}{}
 	for _, fc := range m.Filter {
-		switch fv := fc.Value.(type) {
-		case []string:
-			fcs = append(fcs, fmt.Sprintf("%s,%s,%s", fc.Name, fc.Operator, strings.Join(fv, ",")))
-		case []interface{}:
-			sv := make([]string, len(fv))
-			for i, v := range fv {
-				sv[i] = fmt.Sprintf("%v", v)
-			}
-			fcs = append(fcs, fmt.Sprintf("%s,%s,%s", fc.Name, fc.Operator, strings.Join(sv, ",")))
-		default:
-			fcs = append(fcs, fmt.Sprintf("%s,%s,%v", fc.Name, fc.Operator, fc.Value))
+		if !fc.IsEmptyGroup() {
+			fcs = append(fcs, formatFilterComponent(fc))
 		}
 	}
 	return fcs
@@ -244,7 +245,7 @@ func (m Meta) currentFilter() []interface{} {
 func (m Meta) currentSort() []interface{} {
 	scs := []interface{}{}
 	for _, sc := range m.Sort {
-		scs = append(scs, fmt.Sprintf("%s,%s", sc.Name, sc.Order))
+		scs = append(scs, fmt.Sprintf("%s,%s", escapeComponent(sc.Name), sc.Order))
 	}
 	return scs
 }
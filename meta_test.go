@@ -0,0 +1,178 @@
+package hyper
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMetaQueryRoundTrip(t *testing.T) {
+	values := []string{
+		"plain",
+		"",
+		"Main Street 1, Bonn",
+		`C:\temp\`,
+		`\,`,
+		",,",
+		"50% & more + less = #1?",
+		"a;b(c)",
+		"Köln/äöü",
+	}
+	for _, v := range values {
+		t.Run(v, func(t *testing.T) {
+			m := Meta{
+				Filter: Filter{
+					{Name: "name", Operator: FilterOperatorLike, Value: v},
+					{Name: "a,b", Operator: FilterOperatorIn, Value: []string{v, "x", v}},
+					{Name: "c", Operator: FilterOperatorBetween, Value: []string{v, v + v}},
+					FilterOr(
+						FilterComponent{Name: "d", Operator: FilterOperatorEquals, Value: v},
+						FilterNot(FilterComponent{Name: v + "e", Operator: FilterOperatorIn, Value: []string{v, "y"}}),
+					),
+				},
+				Sort:   Sort{{Name: v + "s", Order: SortOrderDescending}},
+				Search: v,
+				Skip:   10,
+				Limit:  5,
+				After:  v,
+			}
+			u, err := url.Parse("/" + m.Query())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseMeta(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v == "" {
+				// empty parameters are omitted
+				m.Search, m.After = "", ""
+			}
+			if !reflect.DeepEqual(m, got) {
+				t.Errorf("want: %#v, got: %#v", m, got)
+			}
+		})
+	}
+}
+
+func TestMetaQueryEmptyGroups(t *testing.T) {
+	a := FilterComponent{Name: "a", Operator: FilterOperatorEquals, Value: "1"}
+	tests := []struct {
+		name string
+		in   Filter
+		want Filter
+	}{
+		{name: "top level", in: Filter{FilterOr(), a, FilterNot(FilterAnd())}, want: Filter{a}},
+		{name: "nested", in: Filter{FilterAnd(a, FilterNot()), FilterOr(FilterOr(), a)}, want: Filter{FilterAnd(a), FilterOr(a)}},
+		{name: "only", in: Filter{FilterNot()}, want: Filter{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := url.Parse("/" + Meta{Filter: test.in, Limit: 5}.Query())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseMeta(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := Meta{Filter: test.want, Sort: Sort{}, Limit: 5}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("want: %#v, got: %#v", want, got)
+			}
+		})
+	}
+}
+
+func TestParseFilterComponentEscaped(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want FilterComponent
+	}{
+		{raw: `a,eq,x\,y`, want: FilterComponent{Name: "a", Operator: FilterOperatorEquals, Value: "x,y"}},
+		{raw: `a,in,x\,y,z`, want: FilterComponent{Name: "a", Operator: FilterOperatorIn, Value: []string{"x,y", "z"}}},
+		{raw: `a\,b,eq,\\`, want: FilterComponent{Name: "a,b", Operator: FilterOperatorEquals, Value: `\`}},
+		{raw: `a,eq,\x\`, want: FilterComponent{Name: "a", Operator: FilterOperatorEquals, Value: `x\`}},
+		{raw: `a,eq,x;y(z)`, want: FilterComponent{Name: "a", Operator: FilterOperatorEquals, Value: "x;y(z)"}},
+		{raw: `or\(a,eq,1)`, want: FilterComponent{Name: "or(a", Operator: FilterOperatorEquals, Value: "1)"}},
+		{raw: `f(x),eq,1`, want: FilterComponent{Name: "f(x)", Operator: FilterOperatorEquals, Value: "1"}},
+		{raw: `x(a,eq,b)`, want: FilterComponent{Name: "x(a", Operator: FilterOperatorEquals, Value: "b)"}},
+		{
+			raw: `or(a,in,1,2;not(b,eq,x\;y\);c,like,\(%);and(d,eq,1))`,
+			want: FilterOr(
+				FilterComponent{Name: "a", Operator: FilterOperatorIn, Value: []string{"1", "2"}},
+				FilterNot(
+					FilterComponent{Name: "b", Operator: FilterOperatorEquals, Value: "x;y)"},
+					FilterComponent{Name: "c", Operator: FilterOperatorLike, Value: "(%"},
+				),
+				FilterAnd(FilterComponent{Name: "d", Operator: FilterOperatorEquals, Value: "1"}),
+			),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			got, err := ParseFilterComponent(test.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(test.want, got) {
+				t.Errorf("want: %#v, got: %#v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestParseFilterComponentInvalidGroup(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "or(a,eq,b", want: "invalid filter group or(a,eq,b: unbalanced parentheses"},
+		{raw: "or(a,eq,b);c", want: "invalid filter group or(a,eq,b);c: unexpected characters after group: ;c"},
+		{raw: "and(a,eq,b;)", want: "invalid filter group and(a,eq,b;): empty component"},
+		{raw: "or()", want: "invalid filter group or(): no components"},
+		{raw: "or(a,eq)", want: "invalid filter component: [a eq]"},
+		{raw: "not(or(a,eq,1;b,eq,2)", want: "invalid filter group not(or(a,eq,1;b,eq,2): unbalanced parentheses"},
+	}
+	for _, test := range tests {
+		t.Run(test.raw, func(t *testing.T) {
+			_, err := ParseFilterComponent(test.raw)
+			if err == nil {
+				t.Fatalf("want: %s, got: nil", test.want)
+			}
+			if got := err.Error(); got != test.want {
+				t.Errorf("want: %s, got: %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMetaJSONGroups(t *testing.T) {
+	m := Meta{Filter: Filter{
+		{Name: "a", Operator: FilterOperatorEquals, Value: "1"},
+		FilterOr(
+			FilterComponent{Name: "b", Operator: FilterOperatorEquals, Value: "2"},
+			FilterNot(FilterComponent{Name: "c", Operator: FilterOperatorLike, Value: "x"}),
+		),
+	}}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Meta
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, got) {
+		t.Errorf("want: %#v, got: %#v", m, got)
+	}
+}
+
+func TestMakeFilterLinkGroups(t *testing.T) {
+	l := MakeFilterLink(FilterConfiguration{{Name: "a"}}, "{?filter*}", nil, "", FilterOperatorOr, FilterOperatorNot)
+	want := SelectOptions{{Label: "or", Value: FilterOperatorOr}, {Label: "not", Value: FilterOperatorNot}}
+	if got := l.Parameters[0].Options; !reflect.DeepEqual(want, got) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+}
@@ -0,0 +1,130 @@
+package hyper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Encoder encodes an Item in a specific media type.
+type Encoder func(w io.Writer, i Item) error
+
+// EncodeHyperItem encodes an Item as hyper-item.
+func EncodeHyperItem(w io.Writer, i Item) error {
+	return json.NewEncoder(w).Encode(i)
+}
+
+// NewNegotiator creates a Negotiator without any registered encoders.
+func NewNegotiator() *Negotiator {
+	return &Negotiator{}
+}
+
+// Negotiator selects an Encoder based on the Accept header of a request.
+type Negotiator struct {
+	mu       sync.RWMutex
+	encoders []negotiableEncoder
+}
+
+type negotiableEncoder struct {
+	contentType string
+	mediaType   string
+	encode      Encoder
+}
+
+// Register registers an Encoder for the content type. The content type may carry parameters
+// (e.g. charset) which are sent along but ignored during negotiation. Registering a media type
+// again replaces the previous Encoder. If several encoders are equally acceptable the one
+// registered first wins.
+func (n *Negotiator) Register(contentType string, enc Encoder) {
+	typ, sub := splitMediaType(contentType)
+	ne := negotiableEncoder{
+		contentType: contentType,
+		mediaType:   typ + "/" + sub,
+		encode:      enc,
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i, e := range n.encoders {
+		if e.mediaType == ne.mediaType {
+			n.encoders[i] = ne
+			return
+		}
+	}
+	n.encoders = append(n.encoders, ne)
+}
+
+// Negotiate returns the content type and Encoder that best match the Accept header.
+func (n *Negotiator) Negotiate(accept string) (string, Encoder, bool) {
+	mrs := ParseAccept(accept)
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	var best negotiableEncoder
+	bestQ := 0.0
+	for _, e := range n.encoders {
+		if q := AcceptQuality(mrs, e.mediaType); q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+	if bestQ == 0 {
+		return "", nil, false
+	}
+	return best.contentType, best.encode, true
+}
+
+// Respond writes the Item in the representation that best matches the Accept header of
+// the request. It answers with 406 and an error Item if no representation is acceptable.
+func (n *Negotiator) Respond(w http.ResponseWriter, r *http.Request, status int, i Item) {
+	ct, buf, ok := n.encode(w, r, i)
+	if !ok {
+		return
+	}
+	w.Header().Set(HeaderContentType, ct)
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// encode encodes the Item in the negotiated representation. If that fails it answers
+// the request itself and reports false.
+func (n *Negotiator) encode(w http.ResponseWriter, r *http.Request, i Item) (string, *bytes.Buffer, bool) {
+	w.Header().Add(HeaderVary, HeaderAccept)
+	accept := r.Header.Get(HeaderAccept)
+	ct, enc, ok := n.Negotiate(accept)
+	if !ok {
+		WriteFor(w, r, http.StatusNotAcceptable, ErrorItem(fmt.Errorf("not acceptable: %s", accept)))
+		return "", nil, false
+	}
+	buf := &bytes.Buffer{}
+	if err := enc(buf, i); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", nil, false
+	}
+	return ct, buf, true
+}
+
+// DefaultNegotiator is used by Respond. It serves hyper-items, plain JSON and HTML.
+var DefaultNegotiator = newDefaultNegotiator()
+
+func newDefaultNegotiator() *Negotiator {
+	n := NewNegotiator()
+	n.Register(ContentTypeHyperItemUTF8, EncodeHyperItem)
+	n.Register(ContentTypeJSON+";charset=UTF-8", EncodeHyperItem)
+	n.Register(ContentTypeHTMLUTF8, RenderHTML)
+	n.Register(ContentTypeHAL, EncodeHAL)
+	n.Register(ContentTypeSiren, EncodeSiren)
+	n.Register(ContentTypeCollectionJSON, EncodeCollectionJSON)
+	n.Register(ContentTypeJSONAPI, EncodeJSONAPI)
+	return n
+}
+
+// RegisterEncoder registers an Encoder with the DefaultNegotiator.
+func RegisterEncoder(contentType string, enc Encoder) {
+	DefaultNegotiator.Register(contentType, enc)
+}
+
+// Respond writes the Item using the DefaultNegotiator.
+func Respond(w http.ResponseWriter, r *http.Request, status int, i Item) {
+	DefaultNegotiator.Respond(w, r, status, i)
+}
@@ -0,0 +1,54 @@
+package hyper
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRespond(t *testing.T) {
+	n := newDefaultNegotiator()
+	n.Register("text/plain", func(w io.Writer, i Item) error {
+		_, err := io.WriteString(w, i.Label)
+		return err
+	})
+	tests := []struct {
+		accept      string
+		status      int
+		contentType string
+	}{
+		{accept: "", status: http.StatusOK, contentType: ContentTypeHyperItemUTF8},
+		{accept: "*/*", status: http.StatusOK, contentType: ContentTypeHyperItemUTF8},
+		{accept: "application/json", status: http.StatusOK, contentType: "application/json;charset=UTF-8"},
+		{accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", status: http.StatusOK, contentType: ContentTypeHTMLUTF8},
+		{accept: "text/*;q=0.5, application/json;q=0.4", status: http.StatusOK, contentType: ContentTypeHTMLUTF8},
+		{accept: "text/html;q=0.5, text/plain", status: http.StatusOK, contentType: "text/plain"},
+		{accept: "application/json;q=0, */*;q=0.1", status: http.StatusOK, contentType: ContentTypeHyperItemUTF8},
+		{accept: "image/png", status: http.StatusNotAcceptable, contentType: ContentTypeHyperItemUTF8},
+	}
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set(HeaderAccept, test.accept)
+			rec := httptest.NewRecorder()
+			n.Respond(rec, req, http.StatusOK, Item{Label: "foo"})
+			if rec.Code != test.status {
+				t.Errorf("want: %d, got: %d", test.status, rec.Code)
+			}
+			if got := rec.Header().Get(HeaderContentType); got != test.contentType {
+				t.Errorf("want: %s, got: %s", test.contentType, got)
+			}
+			if got := rec.Header().Get(HeaderVary); got != HeaderAccept {
+				t.Errorf("want: %s, got: %s", HeaderAccept, got)
+			}
+			if test.status == http.StatusNotAcceptable {
+				i := Item{}
+				if err := json.NewDecoder(rec.Body).Decode(&i); err != nil || len(i.Errors) != 1 {
+					t.Errorf("expected error item: %v, %#v", err, i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package hyper
+
+// Pagination describes the current page of a result set for PaginationLinks.
+type Pagination struct {
+	// Count is the number of items on the current page.
+	Count int
+	// Total is the total number of items of the result set, nil if unknown.
+	// It is only used for offset pagination.
+	Total *int
+	// FirstKey and LastKey are the keys of the first and the last item on the current page.
+	// If set (or if the Meta has After or Before) cursor pagination is used.
+	FirstKey string
+	LastKey  string
+}
+
+// PaginationLinks builds the first, previous, next and last Links of a paginated result set.
+// The links are resolved by resolve (usually the ExternalURLResolver of the request) and carry
+// the query of the Meta with the paging adjusted; links pointing outside of the result set are omitted.
+//
+// With offset pagination (skip and limit) the last link requires a known Total. Without a known Total
+// the next link is offered as long as the current page is full.
+// With cursor pagination the next link continues after LastKey and the previous link before FirstKey;
+// there is no last link as its cursor is unknown.
+func PaginationLinks(m Meta, resolve Resolver, p Pagination) Links {
+	if m.Limit == 0 {
+		return nil
+	}
+	if p.FirstKey != "" || p.LastKey != "" || m.After != "" || m.Before != "" {
+		return cursorPaginationLinks(m, resolve, p)
+	}
+	return offsetPaginationLinks(m, resolve, p)
+}
+
+func offsetPaginationLinks(m Meta, resolve Resolver, p Pagination) Links {
+	var ls Links
+	if m.Skip > 0 {
+		first := m
+		first.Skip = 0
+		ls = append(ls, paginationLink(RelFirst, first, resolve))
+		ls = append(ls, paginationLink(RelPrevious, m.Previous(), resolve))
+	}
+	next := m.Next()
+	if p.Total != nil && next.Skip < uint64(*p.Total) || p.Total == nil && uint64(p.Count) >= m.Limit {
+		ls = append(ls, paginationLink(RelNext, next, resolve))
+	}
+	if p.Total != nil && *p.Total > 0 {
+		last := m
+		last.Skip = (uint64(*p.Total) - 1) / m.Limit * m.Limit
+		if last.Skip > m.Skip {
+			ls = append(ls, paginationLink(RelLast, last, resolve))
+		}
+	}
+	return ls
+}
+
+func cursorPaginationLinks(m Meta, resolve Resolver, p Pagination) Links {
+	var ls Links
+	full := uint64(p.Count) >= m.Limit
+	start := m
+	start.Skip, start.After, start.Before = 0, "", ""
+	if m.After != "" || m.Before != "" {
+		ls = append(ls, paginationLink(RelFirst, start, resolve))
+	}
+	if p.FirstKey != "" && (m.After != "" || m.Before != "" && full) {
+		ls = append(ls, paginationLink(RelPrevious, start.WithBefore(p.FirstKey), resolve))
+	}
+	if p.LastKey != "" && (m.Before != "" || full) {
+		ls = append(ls, paginationLink(RelNext, start.WithAfter(p.LastKey), resolve))
+	}
+	return ls
+}
+
+func paginationLink(rel string, m Meta, resolve Resolver) Link {
+	q := m.Query()
+	if q == "" {
+		q = "?"
+	}
+	u := resolve.Resolve("%s", q)
+	u.ForceQuery = false
+	return Link{Rel: rel, Href: u.String()}
+}
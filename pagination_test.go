@@ -0,0 +1,107 @@
+package hyper
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPaginationLinks(t *testing.T) {
+	base, _ := url.Parse("http://localhost/orders?skip=10&limit=10")
+	resolve := NewURLResolver(base)
+	tests := []struct {
+		name string
+		meta Meta
+		p    Pagination
+		want Links
+	}{
+		{
+			name: "no limit",
+			meta: Meta{},
+			p:    Pagination{Count: 5, Total: intPtr(5)},
+			want: nil,
+		},
+		{
+			name: "first page",
+			meta: Meta{Limit: 10},
+			p:    Pagination{Count: 10, Total: intPtr(25)},
+			want: Links{
+				{Rel: RelNext, Href: "http://localhost/orders?skip=10&limit=10"},
+				{Rel: RelLast, Href: "http://localhost/orders?skip=20&limit=10"},
+			},
+		},
+		{
+			name: "middle page",
+			meta: Meta{Skip: 10, Limit: 10, Sort: Sort{{Name: "id", Order: SortOrderAscending}}},
+			p:    Pagination{Count: 10, Total: intPtr(25)},
+			want: Links{
+				{Rel: RelFirst, Href: "http://localhost/orders?sort=id%2CASC&limit=10"},
+				{Rel: RelPrevious, Href: "http://localhost/orders?sort=id%2CASC&limit=10"},
+				{Rel: RelNext, Href: "http://localhost/orders?sort=id%2CASC&skip=20&limit=10"},
+				{Rel: RelLast, Href: "http://localhost/orders?sort=id%2CASC&skip=20&limit=10"},
+			},
+		},
+		{
+			name: "last page",
+			meta: Meta{Skip: 20, Limit: 10},
+			p:    Pagination{Count: 5, Total: intPtr(25)},
+			want: Links{
+				{Rel: RelFirst, Href: "http://localhost/orders?limit=10"},
+				{Rel: RelPrevious, Href: "http://localhost/orders?skip=10&limit=10"},
+			},
+		},
+		{
+			name: "unknown total",
+			meta: Meta{Limit: 10},
+			p:    Pagination{Count: 10},
+			want: Links{
+				{Rel: RelNext, Href: "http://localhost/orders?skip=10&limit=10"},
+			},
+		},
+		{
+			name: "empty result",
+			meta: Meta{Limit: 10},
+			p:    Pagination{Total: intPtr(0)},
+			want: nil,
+		},
+		{
+			name: "cursor first page",
+			meta: Meta{Limit: 2},
+			p:    Pagination{Count: 2, FirstKey: "a", LastKey: "b"},
+			want: Links{
+				{Rel: RelNext, Href: "http://localhost/orders?limit=2&after=b"},
+			},
+		},
+		{
+			name: "cursor after",
+			meta: Meta{Limit: 2, After: "b"},
+			p:    Pagination{Count: 1, FirstKey: "c", LastKey: "c"},
+			want: Links{
+				{Rel: RelFirst, Href: "http://localhost/orders?limit=2"},
+				{Rel: RelPrevious, Href: "http://localhost/orders?limit=2&before=c"},
+			},
+		},
+		{
+			name: "cursor before",
+			meta: Meta{Limit: 2, Before: "c"},
+			p:    Pagination{Count: 2, FirstKey: "a", LastKey: "b"},
+			want: Links{
+				{Rel: RelFirst, Href: "http://localhost/orders?limit=2"},
+				{Rel: RelPrevious, Href: "http://localhost/orders?limit=2&before=a"},
+				{Rel: RelNext, Href: "http://localhost/orders?limit=2&after=b"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := PaginationLinks(test.meta, resolve, test.p)
+			if !reflect.DeepEqual(test.want, got) {
+				t.Errorf("\nwant: %v\ngot:  %v", test.want, got)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
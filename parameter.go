@@ -1,5 +1,7 @@
 package hyper
 
+import "encoding/json"
+
 // Parameter .
 type Parameter struct {
 	Label       string        `json:"label,omitempty"`
@@ -89,3 +91,25 @@ func (s SelectOptions) Swap(i, j int) {
 func (s SelectOptions) Less(i, j int) bool {
 	return s[i].Label < s[j].Label
 }
+
+// objectComponents returns the Components of a TypeObject Parameter. They are Parameters
+// when built in code and decoded JSON after a round trip (e.g. of an Item fetched by a Client).
+func objectComponents(p Parameter) Parameters {
+	switch cs := p.Components.(type) {
+	case nil:
+		return nil
+	case Parameters:
+		return cs
+	case []Parameter:
+		return cs
+	}
+	bs, err := json.Marshal(p.Components)
+	if err != nil {
+		return nil
+	}
+	var cs Parameters
+	if err := json.Unmarshal(bs, &cs); err != nil {
+		return nil
+	}
+	return cs
+}
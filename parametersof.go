@@ -0,0 +1,234 @@
+package hyper
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParametersOf derives Parameters from the exported fields of the struct v
+// (or the struct v points to). The `hyper` tag carries the name followed by
+// comma separated flags and settings:
+//
+//	Name  string   `hyper:"name,required,type=text,minlength=1,maxlength=64,placeholder=Jane Doe" label:"Name"`
+//	Age   int      `hyper:"age,min=0,max=150,step=1,value=18"`
+//	Lang  string   `hyper:"lang" options:"de=German,en=English"`
+//	Tags  []string `hyper:"tags" pattern:"[a-z]+"`
+//
+// Supported flags are required, readonly and multiple; supported settings are
+// type, min, max, minlength, maxlength, step, value and placeholder.
+// Free text goes into the label, description, pattern and options tags.
+// Nested structs become parameters of type object with their fields as Components;
+// slices become multiple parameters of their element type.
+// Non-zero field values of v (including those of nested structs) are used as default values.
+func ParametersOf(v interface{}) Parameters {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv = reflect.New(rv.Type().Elem()).Elem()
+			continue
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return parametersOfStruct(rv, map[reflect.Type]bool{})
+}
+
+func parametersOfStruct(sv reflect.Value, seen map[reflect.Type]bool) Parameters {
+	var ps Parameters
+	st := sv.Type()
+	// guard against recursive types
+	seen[st] = true
+	defer delete(seen, st)
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		fv := sv.Field(i)
+		if f.Anonymous && f.Tag.Get(TagName) == "" && indirectType(f.Type).Kind() == reflect.Struct {
+			ps = append(ps, parametersOfStruct(indirectValue(fv), seen)...)
+			continue
+		}
+		ps = append(ps, parameterOfField(f, name, fv, seen))
+	}
+	return ps
+}
+
+func parameterOfField(f reflect.StructField, name string, fv reflect.Value, seen map[reflect.Type]bool) Parameter {
+	p := Parameter{
+		Name:        name,
+		Label:       f.Tag.Get("label"),
+		Description: f.Tag.Get("description"),
+		Pattern:     f.Tag.Get("pattern"),
+	}
+
+	t := f.Type
+	if t == typeFileHeaders {
+		p.Multiple = true
+	} else if t != typeBytes && indirectType(t).Kind() == reflect.Slice {
+		p.Multiple = true
+		t = indirectType(t).Elem()
+	}
+	p.Type = parameterType(t)
+	if st := indirectType(t); p.Type == TypeObject && !seen[st] {
+		sv := reflect.New(st).Elem()
+		if !p.Multiple {
+			// nested values become the default values of the components
+			sv = indirectValue(fv)
+		}
+		p.Components = parametersOfStruct(sv, seen)
+	}
+
+	if options := f.Tag.Get("options"); options != "" {
+		p.Options = parseOptions(options)
+		if p.Type == TypeText {
+			p.Type = TypeSelect
+		}
+	}
+
+	settings := map[string]string{}
+	tag := f.Tag.Get(TagName)
+	if i := strings.Index(tag, ","); i >= 0 {
+		for _, opt := range strings.Split(tag[i+1:], ",") {
+			kv := strings.SplitN(opt, "=", 2)
+			switch {
+			case len(kv) == 2:
+				settings[strings.TrimSpace(kv[0])] = kv[1]
+			case kv[0] == "required":
+				p.Required = true
+			case kv[0] == "readonly":
+				p.ReadOnly = true
+			case kv[0] == "multiple":
+				p.Multiple = true
+			}
+		}
+	}
+	if typ, ok := settings["type"]; ok {
+		p.Type = typ
+	}
+	if v, ok := settings["placeholder"]; ok {
+		p.Placeholder = v
+	}
+	if v, ok := settings["min"]; ok {
+		p.Min = parameterSetting(p.Type, v)
+	}
+	if v, ok := settings["max"]; ok {
+		p.Max = parameterSetting(p.Type, v)
+	}
+	if v, ok := settings["step"]; ok {
+		p.Step = parameterSetting(p.Type, v)
+	}
+	if v, ok := settings["minlength"]; ok {
+		p.MinLength = parameterSetting(TypeInteger, v)
+	}
+	if v, ok := settings["maxlength"]; ok {
+		p.MaxLength = parameterSetting(TypeInteger, v)
+	}
+	if v, ok := settings["value"]; ok {
+		p.Value = parameterSetting(p.Type, v)
+	} else if p.Type != TypeObject && fv.CanInterface() && !isZeroValue(fv) {
+		p.Value = parameterValue(p.Type, fv)
+	}
+	return p
+}
+
+// parameterType maps a Go type to the corresponding parameter type.
+func parameterType(t reflect.Type) string {
+	switch t {
+	case typeTime, reflect.PtrTo(typeTime):
+		return TypeDatetime
+	case typeFileHeader, typeFileHeaders, typeBytes:
+		return TypeFile
+	}
+	switch indirectType(t).Kind() {
+	case reflect.Bool:
+		return TypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypeInteger
+	case reflect.Float32, reflect.Float64:
+		return TypeNumber
+	case reflect.Struct:
+		return TypeObject
+	}
+	return TypeText
+}
+
+// parameterSetting converts a tag setting into a value suitable for the parameter type.
+func parameterSetting(typ string, s string) interface{} {
+	switch typ {
+	case TypeInteger:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	case TypeNumber, TypeRange:
+		if s == "any" {
+			return s
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case TypeBool, TypeCheckbox:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return s
+}
+
+func parameterValue(typ string, fv reflect.Value) interface{} {
+	fv = indirectValue(fv)
+	if t, ok := fv.Interface().(time.Time); ok {
+		switch typ {
+		case TypeDate:
+			return t.Format("2006-01-02")
+		case TypeMonth:
+			return t.Format("2006-01")
+		case TypeTime:
+			return t.Format("15:04:05")
+		case TypeWeek:
+			y, w := t.ISOWeek()
+			return fmt.Sprintf("%04d-W%02d", y, w)
+		}
+		return t.Format(time.RFC3339)
+	}
+	return fv.Interface()
+}
+
+// parseOptions parses options of the form "value=label,value=label" or "value,value".
+func parseOptions(s string) SelectOptions {
+	var os SelectOptions
+	for _, o := range strings.Split(s, ",") {
+		kv := strings.SplitN(o, "=", 2)
+		opt := SelectOption{Value: kv[0], Label: kv[0]}
+		if len(kv) == 2 {
+			opt.Label = kv[1]
+		}
+		os = append(os, opt)
+	}
+	return os
+}
+
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.New(v.Type().Elem()).Elem()
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil() || (v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface && v.Len() == 0)
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
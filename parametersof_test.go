@@ -0,0 +1,87 @@
+package hyper
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParametersOf(t *testing.T) {
+	type line struct {
+		Product  string `hyper:"product,required"`
+		Quantity int    `hyper:"quantity,min=1"`
+	}
+	type address struct {
+		Street string `hyper:"street,required" label:"Street"`
+	}
+	type order struct {
+		Name     string    `hyper:"name,required,maxlength=64,placeholder=Jane Doe" label:"Name" description:"The name"`
+		Email    string    `hyper:"email,type=email"`
+		Count    int       `hyper:"count,min=0,max=10,value=1"`
+		Price    float64   `hyper:"price,step=0.01"`
+		Lang     string    `hyper:"lang" options:"de=German,en=English"`
+		Tags     []string  `hyper:"tags" pattern:"[a-z]+"`
+		Due      time.Time `hyper:"due,type=date"`
+		Express  bool      `hyper:"express"`
+		Address  address   `hyper:"address"`
+		Lines    []line    `hyper:"lines"`
+		internal string
+		Skipped  string `hyper:"-"`
+	}
+
+	due := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+	got := ParametersOf(&order{Name: "Jane", Due: due, Address: address{Street: "Main St"}})
+	want := Parameters{
+		{Name: "name", Type: TypeText, Label: "Name", Description: "The name", Required: true, MaxLength: int64(64), Placeholder: "Jane Doe", Value: "Jane"},
+		{Name: "email", Type: TypeEmail},
+		{Name: "count", Type: TypeInteger, Min: int64(0), Max: int64(10), Value: int64(1)},
+		{Name: "price", Type: TypeNumber, Step: 0.01},
+		{Name: "lang", Type: TypeSelect, Options: SelectOptions{{Label: "German", Value: "de"}, {Label: "English", Value: "en"}}},
+		{Name: "tags", Type: TypeText, Multiple: true, Pattern: "[a-z]+"},
+		{Name: "due", Type: TypeDate, Value: "2020-03-01"},
+		{Name: "express", Type: TypeBool},
+		{Name: "address", Type: TypeObject, Components: Parameters{
+			{Name: "street", Type: TypeText, Label: "Street", Required: true, Value: "Main St"},
+		}},
+		{Name: "lines", Type: TypeObject, Multiple: true, Components: Parameters{
+			{Name: "product", Type: TypeText, Required: true},
+			{Name: "quantity", Type: TypeInteger, Min: int64(1)},
+		}},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant: %#v\ngot:  %#v", want, got)
+	}
+
+	c := MakeCommand()
+	c.Arguments = Arguments{
+		"name":  "Jane",
+		"count": "11",
+		"lines": []interface{}{map[string]interface{}{"quantity": 0.0}},
+	}
+	var codes []string
+	for _, e := range Validate(Action{Parameters: got}, c) {
+		codes = append(codes, e.Name+":"+e.Code)
+	}
+	wantCodes := []string{"count:" + ErrorCodeMax, "lines.product:" + ErrorCodeRequired, "lines.quantity:" + ErrorCodeMin}
+	if !reflect.DeepEqual(wantCodes, codes) {
+		t.Errorf("want: %v, got: %v", wantCodes, codes)
+	}
+
+	// components are validated after a JSON round trip as well
+	bs, err := json.Marshal(Action{Parameters: got})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Action
+	if err := json.Unmarshal(bs, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	codes = nil
+	for _, e := range Validate(decoded, c) {
+		codes = append(codes, e.Name+":"+e.Code)
+	}
+	if !reflect.DeepEqual(wantCodes, codes) {
+		t.Errorf("want: %v, got: %v", wantCodes, codes)
+	}
+}
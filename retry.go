@@ -0,0 +1,180 @@
+package hyper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how the Client retries failed requests.
+//
+// Only idempotent requests are retried: GET, HEAD, OPTIONS, PUT and DELETE as well as
+// submissions of Actions that are marked as Idempotent (e.g. a POST with a client generated ID).
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one (default 3).
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry (default 100ms). It doubles with every
+	// further retry up to MaxBackoff (default 10s). Half of each wait is randomized (jitter).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retryable decides whether an attempt is retried. By default transport errors and the
+	// status codes 429, 502, 503 and 504 are retried.
+	Retryable func(resp *http.Response, err error) bool
+	// OnAttempt is called after every attempt.
+	OnAttempt func(a Attempt)
+}
+
+// Attempt describes a single attempt of a request.
+type Attempt struct {
+	Request  *http.Request
+	Number   int
+	Response *http.Response
+	Err      error
+	// Retry reports whether the request will be retried after Wait.
+	Retry bool
+	Wait  time.Duration
+}
+
+// WithRetry lets the Client retry idempotent requests according to p.
+// A Retry-After header of the response takes precedence over the backoff; if it asks
+// to wait longer than MaxBackoff the request is not retried.
+func WithRetry(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		if p.MaxAttempts <= 0 {
+			p.MaxAttempts = 3
+		}
+		if p.MinBackoff <= 0 {
+			p.MinBackoff = 100 * time.Millisecond
+		}
+		if p.MaxBackoff <= 0 {
+			p.MaxBackoff = 10 * time.Second
+		}
+		if p.Retryable == nil {
+			p.Retryable = DefaultRetryable
+		}
+		c.retry = &p
+	}
+}
+
+// DefaultRetryable retries transport errors and the status codes 429, 502, 503 and 504.
+func DefaultRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		if ue, ok := err.(*url.Error); ok {
+			err = ue.Err
+		}
+		return err != context.Canceled && err != context.DeadlineExceeded
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+type idempotentKey struct{}
+
+// withIdempotent marks the requests of ctx as idempotent.
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	ok, _ := req.Context().Value(idempotentKey{}).(bool)
+	return ok
+}
+
+// sendWithRetry sends the request, retrying it according to the retry policy if it is idempotent.
+func (c *Client) sendWithRetry(req *http.Request) (*http.Response, error) {
+	p := c.retry
+	ctx := req.Context()
+	idempotent := isIdempotent(req)
+	for n := 1; ; n++ {
+		if n > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("do: %v", err)
+			}
+			req.Body = body
+		}
+		resp, err := c.httpClient.Do(req)
+		a := Attempt{
+			Request:  req,
+			Number:   n,
+			Response: resp,
+			Err:      err,
+		}
+		a.Retry = idempotent && n < p.MaxAttempts && ctx.Err() == nil && (req.Body == nil || req.GetBody != nil) && p.Retryable(resp, err)
+		if a.Retry {
+			a.Wait, a.Retry = p.backoff(n, resp)
+		}
+		if p.OnAttempt != nil {
+			p.OnAttempt(a)
+		}
+		if !a.Retry {
+			if err != nil {
+				return nil, fmt.Errorf("do: %v", err)
+			}
+			return resp, nil
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		t := time.NewTimer(a.Wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, fmt.Errorf("do: %v", ctx.Err())
+		case <-t.C:
+		}
+	}
+}
+
+// backoff returns the wait before the next attempt after attempt n.
+// It reports false if the Retry-After header of the response exceeds MaxBackoff.
+func (p *RetryPolicy) backoff(n int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return d, d <= p.MaxBackoff
+		}
+	}
+	d := p.MinBackoff
+	for i := 1; i < n && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1)), true
+}
+
+// retryAfter parses a Retry-After header given in seconds or as HTTP date.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
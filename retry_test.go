@@ -0,0 +1,138 @@
+package hyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientRetry(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		c := ExtractCommand(r)
+		Write(w, http.StatusOK, Item{Label: r.Method + "|" + c.Arguments.String("name")})
+	}))
+	defer s.Close()
+
+	var attempts []Attempt
+	c := NewClient(WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		OnAttempt: func(a Attempt) {
+			attempts = append(attempts, a)
+		},
+	}))
+
+	tests := []struct {
+		name     string
+		action   Action
+		calls    int
+		want     string
+		attempts int
+	}{
+		{name: "get", action: Action{Href: s.URL, Method: http.MethodGet}, calls: 3, want: "GET|", attempts: 3},
+		{name: "post", action: Action{Href: s.URL}, calls: 1, attempts: 1},
+		{name: "idempotent post", action: Action{Href: s.URL, Idempotent: true}, calls: 3, want: "POST|foo", attempts: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls, attempts = 0, nil
+			var got Item
+			var err error
+			if test.action.Method == http.MethodGet {
+				got, err = c.Fetch(test.action.Href)
+			} else {
+				got, err = c.SubmitItem(test.action, Arguments{"name": "foo"})
+			}
+			if test.want == "" {
+				if !IsStatus(err, http.StatusServiceUnavailable) {
+					t.Errorf("want: service unavailable, got: %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if got.Label != test.want {
+				t.Errorf("want: %s, got: %s", test.want, got.Label)
+			}
+			if calls != test.calls {
+				t.Errorf("want: %d calls, got: %d", test.calls, calls)
+			}
+			if len(attempts) != test.attempts {
+				t.Fatalf("want: %d attempts, got: %d", test.attempts, len(attempts))
+			}
+			if last := attempts[len(attempts)-1]; last.Retry {
+				t.Errorf("want: last attempt without retry")
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		n        int
+		min, max time.Duration
+	}{
+		{n: 1, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{n: 2, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{n: 3, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{n: 10, min: 500 * time.Millisecond, max: time.Second},
+	}
+	for _, test := range tests {
+		if got, ok := p.backoff(test.n, nil); !ok || got < test.min || got > test.max {
+			t.Errorf("attempt %d: want: %v..%v, got: %v (%t)", test.n, test.min, test.max, got, ok)
+		}
+	}
+	retryAfterTests := []struct {
+		header string
+		want   time.Duration
+		retry  bool
+	}{
+		{header: "1", want: time.Second, retry: true},
+		{header: "0", want: 0, retry: true},
+		{header: "7", want: 7 * time.Second, retry: false},
+	}
+	for _, test := range retryAfterTests {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("Retry-After", test.header)
+		got, retry := p.backoff(1, resp)
+		if got != test.want || retry != test.retry {
+			t.Errorf("Retry-After %s: want: %v (%t), got: %v (%t)", test.header, test.want, test.retry, got, retry)
+		}
+	}
+}
+
+func TestClientRetryAfterExceedsMaxBackoff(t *testing.T) {
+	var calls int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	var attempts []Attempt
+	c := NewClient(WithRetry(RetryPolicy{
+		MaxBackoff: time.Second,
+		OnAttempt: func(a Attempt) {
+			attempts = append(attempts, a)
+		},
+	}))
+	start := time.Now()
+	_, err := c.Fetch(s.URL)
+	if !IsStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("want: service unavailable, got: %v", err)
+	}
+	if calls != 1 || len(attempts) != 1 || attempts[0].Retry {
+		t.Errorf("want: 1 call without retry, got: %d calls, %#v", calls, attempts)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("want: no wait, got: %v", d)
+	}
+}
@@ -0,0 +1,313 @@
+package hyper
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// ContentTypeSiren is the media type of Siren.
+// See: https://github.com/kevinswiber/siren
+const ContentTypeSiren = "application/vnd.siren+json"
+
+const sirenClassError = "error"
+
+// sirenClassNoTransclude marks embedded representations of sub-Items that are not rendered with RenderTransclude
+// (sub-Items without a self link can not be embedded as links).
+const sirenClassNoTransclude = "no-transclude"
+
+// SirenEntity is a Siren entity. Sub-entities are either embedded links (Href is set)
+// or embedded representations.
+type SirenEntity struct {
+	Class      []string               `json:"class,omitempty"`
+	Rel        []string               `json:"rel,omitempty"`
+	Href       string                 `json:"href,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Entities   []SirenEntity          `json:"entities,omitempty"`
+	Actions    []SirenAction          `json:"actions,omitempty"`
+	Links      []SirenLink            `json:"links,omitempty"`
+}
+
+// SirenAction is a Siren action.
+type SirenAction struct {
+	Name   string       `json:"name"`
+	Class  []string     `json:"class,omitempty"`
+	Method string       `json:"method,omitempty"`
+	Href   string       `json:"href"`
+	Title  string       `json:"title,omitempty"`
+	Type   string       `json:"type,omitempty"`
+	Fields []SirenField `json:"fields,omitempty"`
+}
+
+// SirenField is a field of a Siren action.
+type SirenField struct {
+	Name  string      `json:"name"`
+	Class []string    `json:"class,omitempty"`
+	Type  string      `json:"type,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+	Title string      `json:"title,omitempty"`
+}
+
+// SirenLink is a Siren link.
+type SirenLink struct {
+	Class []string `json:"class,omitempty"`
+	Rel   []string `json:"rel"`
+	Href  string   `json:"href"`
+	Title string   `json:"title,omitempty"`
+	Type  string   `json:"type,omitempty"`
+}
+
+// ItemToSiren converts an Item into a Siren entity.
+//
+// Type becomes the class, Label the title and Properties (plus the fields of a
+// JSON object in Data) the properties. Sub-Items rendered with RenderTransclude become
+// embedded representations, all other sub-Items with a self link become embedded links.
+// Sub-Items without a self link that are not transcluded become embedded representations
+// of class "no-transclude". Other Render values of sub-Items are dropped.
+// Actions become actions named by their Rel with their Parameters as fields. Fields only
+// carry the name, type, value and label of a Parameter; Options, Required, Min, Max, Pattern and
+// the other constraints have no Siren counterpart and are dropped.
+// Links with a Template are emitted with the URI template as href, since Siren
+// has no templated links. Errors become embedded representations of class "error".
+// ID and Description have no Siren counterpart and are dropped.
+func ItemToSiren(i Item) SirenEntity {
+	e := SirenEntity{
+		Title: i.Label,
+	}
+	if i.Type != "" {
+		e.Class = []string{i.Type}
+	}
+	if i.Rel != "" {
+		e.Rel = []string{i.Rel}
+	}
+	props := map[string]interface{}{}
+	if len(i.Data) > 0 {
+		json.Unmarshal(i.Data, &props)
+	}
+	for _, p := range i.Properties {
+		props[p.Name] = p.Value
+	}
+	if len(props) > 0 {
+		e.Properties = props
+	}
+	for _, sub := range i.Items {
+		e.Entities = append(e.Entities, subItemToSiren(sub))
+	}
+	for _, err := range i.Errors {
+		props := map[string]interface{}{"message": err.Message}
+		if err.Code != "" {
+			props["code"] = err.Code
+		}
+		if err.Name != "" {
+			props["name"] = err.Name
+		}
+		e.Entities = append(e.Entities, SirenEntity{
+			Class:      []string{sirenClassError},
+			Rel:        []string{sirenClassError},
+			Title:      err.Label,
+			Properties: props,
+		})
+	}
+	for _, a := range i.Actions {
+		e.Actions = append(e.Actions, actionToSiren(a))
+	}
+	for _, l := range i.Links {
+		e.Links = append(e.Links, SirenLink{
+			Rel:   []string{l.Rel},
+			Href:  templateHref(l),
+			Title: l.Label,
+			Type:  l.Type,
+		})
+	}
+	return e
+}
+
+func subItemToSiren(sub Item) SirenEntity {
+	rel := sub.Rel
+	if rel == "" {
+		rel = RelItem
+	}
+	if self, ok := sub.Links.FindByRel(RelSelf); ok && !containsString(sub.Render, RenderTransclude) {
+		e := SirenEntity{
+			Rel:   []string{rel},
+			Href:  self.Href,
+			Type:  self.Type,
+			Title: sub.Label,
+		}
+		if sub.Type != "" {
+			e.Class = []string{sub.Type}
+		}
+		return e
+	}
+	e := ItemToSiren(sub)
+	e.Rel = []string{rel}
+	if !containsString(sub.Render, RenderTransclude) {
+		e.Class = append(e.Class, sirenClassNoTransclude)
+	}
+	return e
+}
+
+func actionToSiren(a Action) SirenAction {
+	sa := SirenAction{
+		Name:   a.Rel,
+		Method: a.Method,
+		Href:   a.Href,
+		Title:  a.Label,
+		Type:   a.Encoding,
+	}
+	// Siren defaults to GET and form encoding, while Client.Submit defaults to POST and JSON
+	if sa.Method == "" {
+		sa.Method = MethodPOST
+	}
+	if sa.Type == "" {
+		sa.Type = ContentTypeJSON
+	}
+	for _, p := range a.Parameters {
+		sa.Fields = append(sa.Fields, SirenField{
+			Name:  p.Name,
+			Type:  sirenFieldType(p.Type),
+			Value: p.Value,
+			Title: p.Label,
+		})
+	}
+	return sa
+}
+
+// sirenFieldType maps parameter types onto the HTML5 input types used by Siren.
+func sirenFieldType(typ string) string {
+	switch typ {
+	case TypeInteger:
+		return TypeNumber
+	case TypeBool:
+		return TypeCheckbox
+	case TypeDatetime:
+		return "datetime-local"
+	case TypeSelect, TypeDatalist, TypeObject, "":
+		return TypeText
+	}
+	return typ
+}
+
+// SirenToItem converts a Siren entity into an Item.
+// Embedded representations become sub-Items rendered with RenderTransclude (unless of class "no-transclude"),
+// embedded links become sub-Items with a self link.
+func SirenToItem(e SirenEntity) Item {
+	i := Item{
+		Label: e.Title,
+	}
+	if len(e.Class) > 0 {
+		i.Type = e.Class[0]
+	}
+	if len(e.Rel) > 0 {
+		i.Rel = e.Rel[0]
+	}
+	var names []string
+	for n := range e.Properties {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		i.AddProperty(Property{Name: n, Value: e.Properties[n]})
+	}
+	for _, sub := range e.Entities {
+		if containsString(sub.Class, sirenClassError) {
+			err := Error{Label: sub.Title}
+			err.Message, _ = sub.Properties["message"].(string)
+			err.Code, _ = sub.Properties["code"].(string)
+			err.Name, _ = sub.Properties["name"].(string)
+			i.Errors = append(i.Errors, err)
+			continue
+		}
+		var si Item
+		if sub.Href != "" {
+			si = Item{
+				Label: sub.Title,
+				Links: Links{{Rel: RelSelf, Href: sub.Href, Type: sub.Type}},
+			}
+			if len(sub.Class) > 0 {
+				si.Type = sub.Class[0]
+			}
+		} else {
+			transclude := !containsString(sub.Class, sirenClassNoTransclude)
+			if !transclude {
+				sub.Class = removeString(sub.Class, sirenClassNoTransclude)
+			}
+			si = SirenToItem(sub)
+			if transclude {
+				si.Render = []string{RenderTransclude}
+			}
+		}
+		if len(sub.Rel) > 0 && sub.Rel[0] != RelItem {
+			si.Rel = sub.Rel[0]
+		} else {
+			si.Rel = ""
+		}
+		i.AddItem(si)
+	}
+	for _, sa := range e.Actions {
+		a := Action{
+			Rel:      sa.Name,
+			Label:    sa.Title,
+			Method:   sa.Method,
+			Href:     sa.Href,
+			Encoding: sa.Type,
+		}
+		// the defaults of Siren
+		if a.Method == "" {
+			a.Method = MethodGET
+		}
+		if a.Encoding == "" {
+			a.Encoding = ContentTypeURLEncoded
+		}
+		for _, f := range sa.Fields {
+			typ := f.Type
+			if typ == "" {
+				typ = TypeText
+			}
+			a.Parameters = append(a.Parameters, Parameter{
+				Name:  f.Name,
+				Type:  typ,
+				Value: f.Value,
+				Label: f.Title,
+			})
+		}
+		i.AddAction(a)
+	}
+	for _, sl := range e.Links {
+		for _, rel := range sl.Rel {
+			i.AddLink(Link{
+				Rel:   rel,
+				Href:  sl.Href,
+				Label: sl.Title,
+				Type:  sl.Type,
+			})
+		}
+	}
+	return i
+}
+
+// EncodeSiren encodes an Item as Siren entity.
+func EncodeSiren(w io.Writer, i Item) error {
+	return json.NewEncoder(w).Encode(ItemToSiren(i))
+}
+
+// DecodeSiren decodes a Siren entity into an Item.
+func DecodeSiren(r io.Reader) (Item, error) {
+	e := SirenEntity{}
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return Item{}, err
+	}
+	return SirenToItem(e), nil
+}
+
+func removeString(ss []string, s string) []string {
+	var res []string
+	for _, x := range ss {
+		if x != s {
+			res = append(res, x)
+		}
+	}
+	return res
+}
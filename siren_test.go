@@ -0,0 +1,133 @@
+package hyper
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSirenRoundTrip(t *testing.T) {
+	i := Item{
+		Label:      "Order",
+		Type:       "order",
+		Properties: Properties{{Name: "state", Value: "open"}, {Name: "total", Value: 12.5}},
+		Links:      Links{{Rel: RelSelf, Href: "http://localhost/orders/1"}},
+		Actions: Actions{
+			{
+				Rel:      "add-item",
+				Label:    "Add Item",
+				Method:   MethodPOST,
+				Href:     "http://localhost/orders/1/items",
+				Encoding: ContentTypeURLEncoded,
+				Parameters: Parameters{
+					{Name: "product", Type: TypeText, Label: "Product"},
+					{Name: "quantity", Type: TypeNumber, Value: 1.0},
+				},
+			},
+		},
+		Items: Items{
+			{Rel: "customer", Type: "customer", Label: "Jane", Links: Links{{Rel: RelSelf, Href: "http://localhost/customers/1"}}},
+			{Rel: "shipping", Render: []string{RenderTransclude}, Properties: Properties{{Name: "city", Value: "Berlin"}}},
+			{Rel: "note", Label: "Fragile"},
+			{Type: "discount", Properties: Properties{{Name: "percent", Value: 10.0}}},
+		},
+		Errors: Errors{{Message: "out of stock", Code: "stock", Name: "product"}},
+	}
+
+	buf := bytes.Buffer{}
+	if err := EncodeSiren(&buf, i); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeSiren(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(i, got) {
+		t.Errorf("\nwant: %#v\ngot:  %#v", i, got)
+	}
+}
+
+func TestSirenActionDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      string
+		method      string
+		query       string
+		contentType string
+		body        string
+	}{
+		{
+			name:   "defaults",
+			action: `{"name":"search","fields":[{"name":"q"}]}`,
+			method: MethodGET,
+			query:  "page=1&q=shoes",
+		},
+		{
+			name:        "post",
+			action:      `{"name":"search","method":"POST","fields":[{"name":"q"}]}`,
+			method:      MethodPOST,
+			query:       "page=1",
+			contentType: ContentTypeURLEncoded,
+			body:        "q=shoes",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var method, query, contentType, body string
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bs, _ := ioutil.ReadAll(r.Body)
+				method, query, contentType, body = r.Method, r.URL.RawQuery, r.Header.Get(HeaderContentType), string(bs)
+			}))
+			defer s.Close()
+
+			e := `{"actions":[` + strings.Replace(test.action, `"name":"search"`, `"name":"search","href":"`+s.URL+`/search?page=1"`, 1) + `]}`
+			i, err := DecodeSiren(strings.NewReader(e))
+			if err != nil {
+				t.Fatal(err)
+			}
+			a, ok := i.Actions.Find(ActionRelEquals("search"))
+			if !ok {
+				t.Fatal("missing action")
+			}
+			res, err := NewClient().Submit(a, Arguments{"q": "shoes"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if test.method != method {
+				t.Errorf("want: %v, got: %v", test.method, method)
+			}
+			if test.query != query {
+				t.Errorf("want: %v, got: %v", test.query, query)
+			}
+			if test.contentType != contentType {
+				t.Errorf("want: %v, got: %v", test.contentType, contentType)
+			}
+			if test.body != body {
+				t.Errorf("want: %v, got: %v", test.body, body)
+			}
+		})
+	}
+}
+
+func TestSirenActionHyperDefaults(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := EncodeSiren(&buf, Item{Actions: Actions{{Rel: "create", Href: "http://localhost/orders"}}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeSiren(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := got.Actions[0]
+	if a.Method != MethodPOST {
+		t.Errorf("want: %v, got: %v", MethodPOST, a.Method)
+	}
+	if a.Encoding != ContentTypeJSON {
+		t.Errorf("want: %v, got: %v", ContentTypeJSON, a.Encoding)
+	}
+}
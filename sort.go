@@ -3,7 +3,6 @@ package hyper
 import (
 	"fmt"
 	"net/url"
-	"strings"
 )
 
 func ParseSort(url *url.URL) (Sort, error) {
@@ -37,8 +36,10 @@ func (s Sort) FindOne(name string) (SortComponent, bool) {
 	return SortComponent{}, false
 }
 
+// ParseSortComponent parses a sort component of the form name,order.
+// Like in filter components a backslash escapes the following character of the name.
 func ParseSortComponent(rawSC string) (SortComponent, error) {
-	sExpr := strings.Split(rawSC, ",")
+	sExpr := splitEscaped(rawSC, ',')
 	if len(sExpr) != 2 {
 		return SortComponent{}, fmt.Errorf("invalid sort component: %s", sExpr)
 	}
@@ -70,6 +71,51 @@ const (
 
 type SortConfiguration []SortComponentConfiguration
 
+// Validate checks the components of a Sort against the configuration: the name has to be configured
+// and the order has to be one of its Orders (or ascending or descending if there are none).
+// It returns nil if the Sort is valid.
+func (c SortConfiguration) Validate(s Sort) Errors {
+	var errs Errors
+	for _, sc := range s {
+		cc, ok := c.FindOne(sc.Name)
+		if !ok {
+			errs = append(errs, Error{Name: sc.Name, Code: ErrorCodeOption, Message: fmt.Sprintf("sort by %s is not supported", sc.Name)})
+			continue
+		}
+		orders := cc.Orders
+		if len(orders) == 0 {
+			orders = MakeSortOperatorConfigurations()
+		}
+		if !hasSortOrder(orders, sc.Order) {
+			label := cc.Label
+			if label == "" {
+				label = cc.Name
+			}
+			errs = append(errs, Error{Label: cc.Label, Name: sc.Name, Code: ErrorCodeOption, Message: fmt.Sprintf("%s does not support the order %s", label, sc.Order)})
+		}
+	}
+	return errs
+}
+
+// FindOne returns the configuration of the sort component with the name.
+func (c SortConfiguration) FindOne(name string) (SortComponentConfiguration, bool) {
+	for _, cc := range c {
+		if cc.Name == name {
+			return cc, true
+		}
+	}
+	return SortComponentConfiguration{}, false
+}
+
+func hasSortOrder(ocs []SortOrderConfiguration, o SortOrder) bool {
+	for _, oc := range ocs {
+		if oc.Order == o {
+			return true
+		}
+	}
+	return false
+}
+
 type SortComponentConfiguration struct {
 	Label       string                   `json:"label,omitempty"`
 	Description string                   `json:"description,omitempty"`
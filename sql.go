@@ -0,0 +1,416 @@
+package hyper
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SQLPlaceholder renders the placeholder of the n-th (1-based) argument of a query.
+type SQLPlaceholder func(n int) string
+
+// Placeholders of common SQL dialects.
+var (
+	// SQLQuestion renders placeholders as used by MySQL and SQLite: ?
+	SQLQuestion SQLPlaceholder = func(n int) string { return "?" }
+	// SQLDollar renders placeholders as used by PostgreSQL: $1, $2, ...
+	SQLDollar SQLPlaceholder = func(n int) string { return "$" + strconv.Itoa(n) }
+)
+
+// SQLColumn maps a filter and sort name onto a column.
+type SQLColumn struct {
+	// Name is the name used in Filter and Sort.
+	Name string
+	// Column is the column (or expression) used in SQL. It is never taken from the request.
+	Column string
+	// Type is the parameter type of the column (e.g. TypeText, TypeInteger, TypeNumber, TypeBool,
+	// TypeDate or TypeDatetime) used to convert filter values. It defaults to TypeText.
+	Type string
+}
+
+// SQLBuilder translates a Meta into parameterized SQL. Only names listed in Columns
+// may be used for filtering and sorting.
+type SQLBuilder struct {
+	Columns []SQLColumn
+	// Placeholder defaults to SQLQuestion.
+	Placeholder SQLPlaceholder
+	// ArgOffset is the number of arguments preceding the generated clauses in the final query.
+	ArgOffset int
+	// Key is the name of the unique (and not NULL) column used for After and Before and as final
+	// sort criterion of keyset pagination. It defaults to "id".
+	Key string
+	// Table is required for keyset pagination with a Sort to look up the sort values of the cursor row.
+	Table string
+	// Search lists the names of the (text) columns matched by the search term of the Meta.
+	// Without Search columns a Meta with a search term is rejected.
+	Search []string
+}
+
+// SQLQuery holds the generated clauses and their arguments.
+type SQLQuery struct {
+	// Where is the WHERE clause (including the keyword) or empty.
+	Where string
+	// OrderBy is the ORDER BY clause (including the keywords) or empty.
+	OrderBy string
+	// Limit is the LIMIT and OFFSET clause or empty.
+	Limit string
+	Args  []interface{}
+	// Reverse reports that the rows are selected in reverse order (for Before) and have
+	// to be reversed by the caller.
+	Reverse bool
+}
+
+// String joins the clauses of the query.
+func (q SQLQuery) String() string {
+	var cs []string
+	for _, c := range []string{q.Where, q.OrderBy, q.Limit} {
+		if c != "" {
+			cs = append(cs, c)
+		}
+	}
+	return strings.Join(cs, " ")
+}
+
+// Build translates the filter, search, sort and paging of the Meta into SQL clauses following the
+// rules described for Meta, so that it selects the same elements as the Evaluator.
+// Filter values are converted according to the Type of their column and passed as arguments.
+// Like patterns are matched ignoring case with % as the only wildcard (see likePattern); without
+// wildcards they match substrings; like, nlike and the search apply to text columns only. Groups become
+// parenthesized AND, OR and NOT expressions; within NOT the components exclude NULL explicitly.
+// Unknown names, invalid values and a search term without Search columns are reported as Errors.
+//
+// NULL sorts first in both orders (emitted as CASE expression, as NULLS FIRST is not supported by MySQL).
+// After and Before use keyset pagination on the Sort followed by the Key; Skip is ignored then.
+// The sorted columns must not be NULL for keyset pagination.
+// A Skip without Limit is emitted with the largest LIMIT (as OFFSET requires LIMIT in MySQL and SQLite).
+func (b SQLBuilder) Build(m Meta) (SQLQuery, error) {
+	s := &sqlState{builder: b}
+	var conds []string
+	if c := s.filter(m.Filter); c != "" {
+		conds = append(conds, c)
+	}
+	if m.Search != "" {
+		if len(b.Search) == 0 {
+			s.errs = append(s.errs, Error{Name: "search", Code: ErrorCodeOption, Message: "search is not supported"})
+		}
+		var ors []string
+		for _, name := range b.Search {
+			col, ok := s.column(name, "search")
+			if !ok || !s.text(col, "search") {
+				continue
+			}
+			ors = append(ors, "LOWER("+col.Column+") LIKE "+s.arg(likePattern(m.Search))+sqlLikeEscape)
+		}
+		if len(ors) > 0 {
+			conds = append(conds, "("+strings.Join(ors, " OR ")+")")
+		}
+	}
+
+	order := s.order(m.Sort, m.After != "" || m.Before != "")
+	q := SQLQuery{}
+	switch {
+	case m.Before != "":
+		q.Reverse = true
+		for i := range order {
+			order[i].desc = !order[i].desc
+		}
+		conds = append(conds, s.keyset(order, m.Before))
+	case m.After != "":
+		conds = append(conds, s.keyset(order, m.After))
+	}
+	if len(s.errs) > 0 {
+		return SQLQuery{}, s.errs
+	}
+
+	if len(conds) > 0 {
+		q.Where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	if len(order) > 0 {
+		var os []string
+		for _, o := range order {
+			if o.col.Name != s.key() {
+				// NULL first, or last if the rows are reversed
+				nulls := "CASE WHEN " + o.col.Column + " IS NULL THEN 0 ELSE 1 END"
+				if q.Reverse {
+					nulls += " DESC"
+				}
+				os = append(os, nulls)
+			}
+			dir := "ASC"
+			if o.desc {
+				dir = "DESC"
+			}
+			os = append(os, o.col.Column+" "+dir)
+		}
+		q.OrderBy = "ORDER BY " + strings.Join(os, ", ")
+	}
+	var ls []string
+	skip := m.Skip > 0 && m.After == "" && m.Before == ""
+	switch {
+	case m.Limit > 0:
+		ls = append(ls, "LIMIT "+strconv.FormatUint(m.Limit, 10))
+	case skip:
+		ls = append(ls, "LIMIT "+strconv.FormatInt(math.MaxInt64, 10))
+	}
+	if skip {
+		ls = append(ls, "OFFSET "+strconv.FormatUint(m.Skip, 10))
+	}
+	q.Limit = strings.Join(ls, " ")
+	q.Args = s.args
+	return q, nil
+}
+
+type sqlState struct {
+	builder SQLBuilder
+	args    []interface{}
+	errs    Errors
+	// negated is the depth of NOT groups around the current component.
+	negated int
+}
+
+type sqlOrder struct {
+	col  SQLColumn
+	desc bool
+}
+
+func (s *sqlState) arg(v interface{}) string {
+	s.args = append(s.args, v)
+	p := s.builder.Placeholder
+	if p == nil {
+		p = SQLQuestion
+	}
+	return p(s.builder.ArgOffset + len(s.args))
+}
+
+func (s *sqlState) column(name string, usage string) (SQLColumn, bool) {
+	for _, c := range s.builder.Columns {
+		if c.Name == name {
+			if c.Type == "" {
+				c.Type = TypeText
+			}
+			return c, true
+		}
+	}
+	s.errs = append(s.errs, Error{
+		Name:    name,
+		Code:    ErrorCodeOption,
+		Message: fmt.Sprintf("%s by %q is not supported", usage, name),
+	})
+	return SQLColumn{}, false
+}
+
+// text reports whether the column is a text column and records an error otherwise.
+func (s *sqlState) text(col SQLColumn, usage string) bool {
+	if isTextType(col.Type) {
+		return true
+	}
+	s.errs = append(s.errs, Error{
+		Name:    col.Name,
+		Code:    ErrorCodeOption,
+		Message: fmt.Sprintf("%s by %q requires a text column", usage, col.Name),
+	})
+	return false
+}
+
+func (s *sqlState) value(col SQLColumn, v interface{}) interface{} {
+	tv, err := typedValue(col.Type, v)
+	if err != nil {
+		s.errs = append(s.errs, Error{Name: col.Name, Code: ErrorCodeType, Message: fmt.Sprintf("filter %s: %v", col.Name, err)})
+	}
+	return tv
+}
+
+func (s *sqlState) filter(f Filter) string {
+	var conds []string
+	for _, fc := range f {
+		if c := s.component(fc); c != "" {
+			conds = append(conds, c)
+		}
+	}
+	return strings.Join(conds, " AND ")
+}
+
+func (s *sqlState) component(fc FilterComponent) string {
+	if fc.IsEmptyGroup() {
+		return ""
+	}
+	switch fc.Operator {
+	case FilterOperatorAnd, FilterOperatorNot:
+		if fc.Operator == FilterOperatorNot {
+			s.negated++
+			defer func() { s.negated-- }()
+		}
+		c := s.filter(fc.Components())
+		if c == "" {
+			return ""
+		}
+		if fc.Operator == FilterOperatorNot {
+			return "NOT (" + c + ")"
+		}
+		return "(" + c + ")"
+	case FilterOperatorOr:
+		var ors []string
+		for _, sub := range fc.Components() {
+			if c := s.component(sub); c != "" {
+				ors = append(ors, c)
+			}
+		}
+		if len(ors) == 0 {
+			return ""
+		}
+		return "(" + strings.Join(ors, " OR ") + ")"
+	}
+	col, ok := s.column(fc.Name, "filter")
+	if !ok {
+		return ""
+	}
+	c := s.predicate(col, fc)
+	if c != "" && s.negated > 0 {
+		// NULL must not match within NOT either
+		c = "(" + col.Column + " IS NOT NULL AND " + c + ")"
+	}
+	return c
+}
+
+func (s *sqlState) predicate(col SQLColumn, fc FilterComponent) string {
+	switch fc.Operator {
+	case FilterOperatorEquals, "":
+		return col.Column + " = " + s.arg(s.value(col, fc.Value))
+	case FilterOperatorNotEquals:
+		return col.Column + " <> " + s.arg(s.value(col, fc.Value))
+	case FilterOperatorLessThen:
+		return col.Column + " < " + s.arg(s.value(col, fc.Value))
+	case FilterOperatorLessThenOrEquals:
+		return col.Column + " <= " + s.arg(s.value(col, fc.Value))
+	case FilterOperatorGreaterThen:
+		return col.Column + " > " + s.arg(s.value(col, fc.Value))
+	case FilterOperatorGreaterThenOrEquals:
+		return col.Column + " >= " + s.arg(s.value(col, fc.Value))
+	case FilterOperatorIn, FilterOperatorNotIn:
+		vs := filterValues(fc.Value)
+		if len(vs) == 0 {
+			if fc.Operator == FilterOperatorIn {
+				return "1 = 0"
+			}
+			return col.Column + " IS NOT NULL"
+		}
+		var ps []string
+		for _, v := range vs {
+			ps = append(ps, s.arg(s.value(col, v)))
+		}
+		op := " IN "
+		if fc.Operator == FilterOperatorNotIn {
+			op = " NOT IN "
+		}
+		return col.Column + op + "(" + strings.Join(ps, ", ") + ")"
+	case FilterOperatorBetween, FilterOperatorNotBetween:
+		vs := filterValues(fc.Value)
+		if len(vs) != 2 {
+			s.errs = append(s.errs, Error{Name: fc.Name, Code: ErrorCodeType, Message: fmt.Sprintf("filter %s: invalid size of filter-between values (must == 2): %d", fc.Name, len(vs))})
+			return ""
+		}
+		op := " BETWEEN "
+		if fc.Operator == FilterOperatorNotBetween {
+			op = " NOT BETWEEN "
+		}
+		return col.Column + op + s.arg(s.value(col, vs[0])) + " AND " + s.arg(s.value(col, vs[1]))
+	case FilterOperatorLike, FilterOperatorNotLike:
+		if !s.text(col, "filter") {
+			return ""
+		}
+		pattern, _ := toString(fc.Value)
+		op := " LIKE "
+		if fc.Operator == FilterOperatorNotLike {
+			op = " NOT LIKE "
+		}
+		return "LOWER(" + col.Column + ")" + op + s.arg(likePattern(pattern)) + sqlLikeEscape
+	}
+	s.errs = append(s.errs, Error{Name: fc.Name, Code: ErrorCodeOption, Message: fmt.Sprintf("filter %s: unsupported operator: %s", fc.Name, fc.Operator)})
+	return ""
+}
+
+// sqlLikeEscape is the ESCAPE clause of LIKE. The escape character is ! rather than \, which
+// MySQL treats as escape within string literals.
+const sqlLikeEscape = " ESCAPE '!'"
+
+// likePattern converts a like value into a lower case SQL LIKE pattern (escaped with !).
+// % is kept as wildcard, so there is no way to match a literal %; a value without % matches substrings.
+func likePattern(v string) string {
+	r := strings.NewReplacer(`!`, `!!`, `_`, `!_`)
+	p := r.Replace(strings.ToLower(v))
+	if !strings.Contains(p, "%") {
+		p = "%" + p + "%"
+	}
+	return p
+}
+
+// order resolves the Sort. For keyset pagination the Key is appended to make the order unique.
+func (s *sqlState) order(sort Sort, keyset bool) []sqlOrder {
+	var os []sqlOrder
+	hasKey := false
+	for _, sc := range sort {
+		col, ok := s.column(sc.Name, "sort")
+		if !ok {
+			continue
+		}
+		hasKey = hasKey || col.Name == s.key()
+		os = append(os, sqlOrder{col: col, desc: sc.Order == SortOrderDescending})
+	}
+	if keyset && !hasKey {
+		if col, ok := s.column(s.key(), "sort"); ok {
+			os = append(os, sqlOrder{col: col})
+		}
+	}
+	return os
+}
+
+func (s *sqlState) key() string {
+	if s.builder.Key != "" {
+		return s.builder.Key
+	}
+	return "id"
+}
+
+// keyset builds the predicate selecting the rows following the row with the key in the order.
+//
+//	(c1 > v1) OR (c1 = v1 AND c2 > v2) OR ...
+//
+// where vi is the value of ci in the row with the key.
+func (s *sqlState) keyset(order []sqlOrder, key string) string {
+	if len(order) == 0 {
+		return ""
+	}
+	keyCol, ok := s.column(s.key(), "sort")
+	if !ok {
+		return ""
+	}
+	if len(order) == 1 && order[0].col.Name == keyCol.Name {
+		op := " > "
+		if order[0].desc {
+			op = " < "
+		}
+		return keyCol.Column + op + s.arg(s.value(keyCol, key))
+	}
+	if s.builder.Table == "" {
+		s.errs = append(s.errs, Error{Name: keyCol.Name, Code: ErrorCodeOption, Message: "keyset pagination with a sort requires a table"})
+		return ""
+	}
+	cursor := func(col SQLColumn) string {
+		return "(SELECT " + col.Column + " FROM " + s.builder.Table + " WHERE " + keyCol.Column + " = " + s.arg(s.value(keyCol, key)) + ")"
+	}
+	var ors []string
+	for i, o := range order {
+		var ands []string
+		for _, prev := range order[:i] {
+			ands = append(ands, prev.col.Column+" = "+cursor(prev.col))
+		}
+		op := " > "
+		if o.desc {
+			op = " < "
+		}
+		ands = append(ands, o.col.Column+op+cursor(o.col))
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")"
+}
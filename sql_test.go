@@ -0,0 +1,337 @@
+package hyper
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSQLBuilder(t *testing.T) {
+	b := SQLBuilder{
+		Columns: []SQLColumn{
+			{Name: "id", Column: "o.id", Type: TypeInteger},
+			{Name: "customer", Column: "o.customer_name"},
+			{Name: "total", Column: "o.total", Type: TypeNumber},
+			{Name: "paid", Column: "o.paid", Type: TypeBool},
+			{Name: "created", Column: "o.created_at", Type: TypeDate},
+		},
+		Table:  "orders o",
+		Search: []string{"customer"},
+	}
+	day := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		query string
+		b     func(SQLBuilder) SQLBuilder
+		want  string
+		args  []interface{}
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "filter",
+			query: "filter=total,geq,10&filter=paid,eq,true&filter=created,lt,2020-01-02",
+			want:  "WHERE o.total >= ? AND o.paid = ? AND o.created_at < ?",
+			args:  []interface{}{float64(10), true, day},
+		},
+		{
+			name:  "in and between",
+			query: "filter=id,in,1,2,3&filter=total,nbet,5,10",
+			want:  "WHERE o.id IN (?, ?, ?) AND o.total NOT BETWEEN ? AND ?",
+			args:  []interface{}{int64(1), int64(2), int64(3), float64(5), float64(10)},
+		},
+		{
+			name:  "like",
+			query: "filter=customer,like,Al_%25&filter=customer,nlike,50!\\\\x",
+			want:  `WHERE LOWER(o.customer_name) LIKE ? ESCAPE '!' AND LOWER(o.customer_name) NOT LIKE ? ESCAPE '!'`,
+			args:  []interface{}{`al!_%`, `%50!!\x%`},
+		},
+		{
+			name:  "groups",
+			query: "filter=" + url.QueryEscape("or(paid,eq,true;and(total,gt,10;not(customer,like,bob)))") + "&filter=id,lt,9",
+			want:  `WHERE (o.paid = ? OR (o.total > ? AND NOT ((o.customer_name IS NOT NULL AND LOWER(o.customer_name) LIKE ? ESCAPE '!')))) AND o.id < ?`,
+			args:  []interface{}{true, float64(10), "%bob%", int64(9)},
+		},
+		{
+			name:  "search",
+			query: "search=Bob",
+			want:  `WHERE (LOWER(o.customer_name) LIKE ? ESCAPE '!')`,
+			args:  []interface{}{"%bob%"},
+		},
+		{
+			name:  "dollar placeholders",
+			query: "filter=id,neq,1&filter=total,bet,5,10",
+			b: func(b SQLBuilder) SQLBuilder {
+				b.Placeholder = SQLDollar
+				b.ArgOffset = 1
+				return b
+			},
+			want: "WHERE o.id <> $2 AND o.total BETWEEN $3 AND $4",
+			args: []interface{}{int64(1), float64(5), float64(10)},
+		},
+		{
+			name:  "sort and offset",
+			query: "sort=total,DESC&sort=customer,ASC&skip=20&limit=10",
+			want:  "ORDER BY CASE WHEN o.total IS NULL THEN 0 ELSE 1 END, o.total DESC, CASE WHEN o.customer_name IS NULL THEN 0 ELSE 1 END, o.customer_name ASC LIMIT 10 OFFSET 20",
+		},
+		{
+			name:  "offset without limit",
+			query: "skip=20",
+			want:  "LIMIT 9223372036854775807 OFFSET 20",
+		},
+		{
+			name:  "after key",
+			query: "after=7&skip=20&limit=10",
+			want:  "WHERE o.id > ? ORDER BY o.id ASC LIMIT 10",
+			args:  []interface{}{int64(7)},
+		},
+		{
+			name:  "before key",
+			query: "before=7&sort=id,DESC&limit=10",
+			want:  "WHERE o.id > ? ORDER BY o.id ASC LIMIT 10",
+			args:  []interface{}{int64(7)},
+		},
+		{
+			name:  "after sort",
+			query: "filter=paid,eq,true&sort=total,DESC&after=7",
+			want:  "WHERE o.paid = ? AND ((o.total < (SELECT o.total FROM orders o WHERE o.id = ?)) OR (o.total = (SELECT o.total FROM orders o WHERE o.id = ?) AND o.id > (SELECT o.id FROM orders o WHERE o.id = ?))) ORDER BY CASE WHEN o.total IS NULL THEN 0 ELSE 1 END, o.total DESC, o.id ASC",
+			args:  []interface{}{true, int64(7), int64(7), int64(7)},
+		},
+		{
+			name:  "before sort",
+			query: "sort=total,DESC&before=7&limit=10",
+			want:  "WHERE ((o.total > (SELECT o.total FROM orders o WHERE o.id = ?)) OR (o.total = (SELECT o.total FROM orders o WHERE o.id = ?) AND o.id < (SELECT o.id FROM orders o WHERE o.id = ?))) ORDER BY CASE WHEN o.total IS NULL THEN 0 ELSE 1 END DESC, o.total ASC, o.id DESC LIMIT 10",
+			args:  []interface{}{int64(7), int64(7), int64(7)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, _ := url.Parse("/?" + test.query)
+			m, err := ParseMeta(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tb := b
+			if test.b != nil {
+				tb = test.b(b)
+			}
+			q, err := tb.Build(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.String(); got != test.want {
+				t.Errorf("want: %s, got: %s", test.want, got)
+			}
+			if !reflect.DeepEqual(test.args, q.Args) {
+				t.Errorf("want: %#v, got: %#v", test.args, q.Args)
+			}
+			if q.Reverse != (m.Before != "") {
+				t.Errorf("want: reverse %t, got: %t", m.Before != "", q.Reverse)
+			}
+		})
+	}
+}
+
+func TestSQLBuilderErrors(t *testing.T) {
+	b := SQLBuilder{
+		Columns: []SQLColumn{
+			{Name: "id", Column: "id", Type: TypeInteger},
+			{Name: "total", Column: "total", Type: TypeNumber},
+		},
+	}
+	tests := []struct {
+		name string
+		meta Meta
+		want Errors
+	}{
+		{
+			name: "unknown filter",
+			meta: Meta{Filter: Filter{{Name: "name; DROP TABLE x", Operator: FilterOperatorEquals, Value: "a"}}},
+			want: Errors{{Name: "name; DROP TABLE x", Code: ErrorCodeOption}},
+		},
+		{
+			name: "invalid value",
+			meta: Meta{Filter: Filter{{Name: "total", Operator: FilterOperatorGreaterThen, Value: "ten"}}},
+			want: Errors{{Name: "total", Code: ErrorCodeType}},
+		},
+		{
+			name: "unknown sort",
+			meta: Meta{Sort: Sort{{Name: "secret"}}},
+			want: Errors{{Name: "secret", Code: ErrorCodeOption}},
+		},
+		{
+			name: "search without columns",
+			meta: Meta{Search: "x"},
+			want: Errors{{Name: "search", Code: ErrorCodeOption}},
+		},
+		{
+			name: "like number",
+			meta: Meta{Filter: Filter{{Name: "total", Operator: FilterOperatorLike, Value: "1%"}}},
+			want: Errors{{Name: "total", Code: ErrorCodeOption}},
+		},
+		{
+			name: "keyset without table",
+			meta: Meta{Sort: Sort{{Name: "total"}}, After: "1"},
+			want: Errors{{Name: "id", Code: ErrorCodeOption}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := b.Build(test.meta)
+			errs, ok := err.(Errors)
+			if !ok {
+				t.Fatalf("want: Errors, got: %v", err)
+			}
+			if len(errs) != len(test.want) {
+				t.Fatalf("want: %v, got: %v", test.want, errs)
+			}
+			for i := range errs {
+				if errs[i].Name != test.want[i].Name || errs[i].Code != test.want[i].Code {
+					t.Errorf("want: %v, got: %v", test.want[i], errs[i])
+				}
+			}
+		})
+	}
+}
+
+// TestSQLBuilderEvaluator checks the SQL generated for edge cases of the rules described for Meta
+// along with the rows the Evaluator selects for the same Meta.
+func TestSQLBuilderEvaluator(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"id": int64(1), "customer": "Alice", "total": 9.5, "paid": true},
+		{"id": int64(2), "customer": "al_x", "total": 20.0, "paid": false},
+		{"id": int64(3), "customer": "Bob!", "total": 100.0, "paid": true},
+		{"id": int64(4), "customer": `C:\temp`, "total": 20.0, "paid": false},
+		{"id": int64(5), "customer": nil, "total": 15.0, "paid": true},
+		{"id": int64(6), "customer": "50% off", "total": 7.0, "paid": false},
+	}
+	b := SQLBuilder{
+		Columns: []SQLColumn{
+			{Name: "id", Column: "o.id", Type: TypeInteger},
+			{Name: "customer", Column: "o.customer"},
+			{Name: "total", Column: "o.total", Type: TypeNumber},
+			{Name: "paid", Column: "o.paid", Type: TypeBool},
+		},
+		Table: "orders o",
+	}
+	customer := func(op FilterOperator, v interface{}) FilterComponent {
+		return FilterComponent{Name: "customer", Operator: op, Value: v}
+	}
+	tests := []struct {
+		name string
+		meta Meta
+		sql  string
+		args []interface{}
+		want []string
+	}{
+		{
+			name: "like underscore",
+			meta: Meta{Filter: Filter{customer(FilterOperatorLike, "al_")}},
+			sql:  "WHERE LOWER(o.customer) LIKE ? ESCAPE '!' ORDER BY o.id ASC",
+			args: []interface{}{"%al!_%"},
+			want: []string{"2"},
+		},
+		{
+			name: "like escape character",
+			meta: Meta{Filter: Filter{customer(FilterOperatorLike, "bob!")}},
+			sql:  "WHERE LOWER(o.customer) LIKE ? ESCAPE '!' ORDER BY o.id ASC",
+			args: []interface{}{"%bob!!%"},
+			want: []string{"3"},
+		},
+		{
+			name: "like backslash",
+			meta: Meta{Filter: Filter{customer(FilterOperatorLike, `\temp`)}},
+			sql:  "WHERE LOWER(o.customer) LIKE ? ESCAPE '!' ORDER BY o.id ASC",
+			args: []interface{}{`%\temp%`},
+			want: []string{"4"},
+		},
+		{
+			name: "not like excludes null",
+			meta: Meta{Filter: Filter{customer(FilterOperatorNotLike, "a%")}},
+			sql:  "WHERE LOWER(o.customer) NOT LIKE ? ESCAPE '!' ORDER BY o.id ASC",
+			args: []interface{}{"a%"},
+			want: []string{"3", "4", "6"},
+		},
+		{
+			name: "not includes null",
+			meta: Meta{Filter: Filter{FilterNot(customer(FilterOperatorEquals, "Bob!"))}},
+			sql:  "WHERE NOT ((o.customer IS NOT NULL AND o.customer = ?)) ORDER BY o.id ASC",
+			args: []interface{}{"Bob!"},
+			want: []string{"1", "2", "4", "5", "6"},
+		},
+		{
+			name: "empty nin",
+			meta: Meta{Filter: Filter{customer(FilterOperatorNotIn, []string{})}},
+			sql:  "WHERE o.customer IS NOT NULL ORDER BY o.id ASC",
+			want: []string{"1", "2", "3", "4", "6"},
+		},
+		{
+			name: "empty groups",
+			meta: Meta{Filter: Filter{FilterOr(FilterNot()), FilterAnd()}},
+			sql:  "ORDER BY o.id ASC",
+			want: []string{"1", "2", "3", "4", "5", "6"},
+		},
+		{
+			name: "numbers",
+			meta: Meta{Filter: Filter{{Name: "total", Operator: FilterOperatorBetween, Value: []string{"10", "20"}}, {Name: "paid", Operator: FilterOperatorEquals, Value: "false"}}},
+			sql:  "WHERE o.total BETWEEN ? AND ? AND o.paid = ? ORDER BY o.id ASC",
+			args: []interface{}{float64(10), float64(20), false},
+			want: []string{"2", "4"},
+		},
+		{
+			name: "null first ascending",
+			meta: Meta{Sort: Sort{{Name: "customer", Order: SortOrderAscending}}},
+			sql:  "ORDER BY CASE WHEN o.customer IS NULL THEN 0 ELSE 1 END, o.customer ASC",
+			want: []string{"5", "6", "1", "3", "4", "2"},
+		},
+		{
+			name: "null first descending",
+			meta: Meta{Sort: Sort{{Name: "customer", Order: SortOrderDescending}}},
+			sql:  "ORDER BY CASE WHEN o.customer IS NULL THEN 0 ELSE 1 END, o.customer DESC",
+			want: []string{"5", "2", "4", "3", "1", "6"},
+		},
+		{
+			name: "skip without limit",
+			meta: Meta{Skip: 4},
+			sql:  "ORDER BY o.id ASC LIMIT 9223372036854775807 OFFSET 4",
+			want: []string{"5", "6"},
+		},
+		{
+			name: "after ignores skip",
+			meta: Meta{After: "2", Skip: 1, Limit: 2},
+			sql:  "WHERE o.id > ? ORDER BY o.id ASC LIMIT 2",
+			args: []interface{}{int64(2)},
+			want: []string{"3", "4"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := test.meta.DefaultSort(Sort{{Name: "id", Order: SortOrderAscending}})
+			q, err := b.Build(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.String(); got != test.sql {
+				t.Errorf("want: %s, got: %s", test.sql, got)
+			}
+			if !reflect.DeepEqual(test.args, q.Args) {
+				t.Errorf("want: %#v, got: %#v", test.args, q.Args)
+			}
+
+			evaluated := append([]map[string]interface{}(nil), rows...)
+			if _, err := ApplyMeta(m, &evaluated); err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, r := range evaluated {
+				ids = append(ids, fmt.Sprint(r["id"]))
+			}
+			if !reflect.DeepEqual(test.want, ids) {
+				t.Errorf("want: %v, got: %v", test.want, ids)
+			}
+		})
+	}
+}
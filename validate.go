@@ -0,0 +1,278 @@
+package hyper
+
+import (
+	"fmt"
+	"math"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+// Validate checks the arguments of a Command against the Parameters declared by an Action.
+// It returns nil if all arguments satisfy their constraints.
+func Validate(a Action, c Command) Errors {
+	var errs Errors
+	for _, p := range a.Parameters {
+		errs = append(errs, ValidateParameter(p, c.Arguments)...)
+	}
+	return errs
+}
+
+// ValidateParameter checks the argument named by the Parameter against its constraints.
+func ValidateParameter(p Parameter, args Arguments) Errors {
+	switch p.Type {
+	case TypeButton, TypeSubmit, TypeReset, TypeImage:
+		return nil
+	}
+	if p.Name == NameAction {
+		return nil
+	}
+	v, ok := args[p.Name]
+	if !ok && p.Type == TypeObject {
+		// form encoded objects use prefixed names
+		if sub := args.prefixed(p.Name + "."); len(sub) > 0 {
+			v, ok = map[string]interface{}(sub), true
+		}
+	}
+	if !ok || isEmptyValue(v) {
+		if p.Required {
+			return Errors{parameterError(p, ErrorCodeRequired, "%s is required", parameterLabel(p))}
+		}
+		return nil
+	}
+	vs, isMultiple := multipleValues(v)
+	if !isMultiple {
+		return validateValue(p, v)
+	}
+	if !p.Multiple {
+		return Errors{parameterError(p, ErrorCodeMultiple, "%s does not allow multiple values", parameterLabel(p))}
+	}
+	var errs Errors
+	for _, v := range vs {
+		errs = append(errs, validateValue(p, v)...)
+	}
+	return errs
+}
+
+func validateValue(p Parameter, v interface{}) Errors {
+	label := parameterLabel(p)
+	switch p.Type {
+	case TypeNumber, TypeRange:
+		f, ok := toFloat64(v)
+		if !ok {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be a number", label)}
+		}
+		return validateNumber(p, f)
+	case TypeInteger:
+		i, ok := toInt64(v)
+		if !ok {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be an integer", label)}
+		}
+		return validateNumber(p, float64(i))
+	case TypeBool, TypeCheckbox:
+		if _, ok := toBool(v); !ok {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be a boolean", label)}
+		}
+		return nil
+	case TypeDate, TypeDatetime, TypeMonth, TypeWeek, TypeTime:
+		if t, ok := v.(time.Time); ok {
+			return validateTime(p, t)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be a %s", label, p.Type)}
+		}
+		t, err := parseTimeValue(p.Type, s)
+		if err != nil {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be a %s", label, p.Type)}
+		}
+		return validateTime(p, t)
+	case TypeFile:
+		switch v.(type) {
+		case *multipart.FileHeader, string:
+			return nil
+		}
+		return Errors{parameterError(p, ErrorCodeType, "%s must be a file", label)}
+	case TypeObject:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be an object", label)}
+		}
+		var errs Errors
+		for _, c := range objectComponents(p) {
+			for _, e := range ValidateParameter(c, Arguments(m)) {
+				e.Name = p.Name + "." + e.Name
+				errs = append(errs, e)
+			}
+		}
+		return errs
+	case TypeSelect, TypeRadio:
+		if len(p.Options) > 0 && !hasOption(p.Options, v) {
+			return Errors{parameterError(p, ErrorCodeOption, "%s is not a valid option", label)}
+		}
+		return nil
+	}
+
+	s, ok := toString(v)
+	if !ok {
+		return Errors{parameterError(p, ErrorCodeType, "%s must be a text", label)}
+	}
+	switch p.Type {
+	case TypeEmail:
+		if a, err := mail.ParseAddress(s); err != nil || a.Address != s {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be an e-mail address", label)}
+		}
+	case TypeURL:
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be an absolute url", label)}
+		}
+	case TypeColor:
+		if !colorPattern.MatchString(s) {
+			return Errors{parameterError(p, ErrorCodeType, "%s must be a color of the form #rrggbb", label)}
+		}
+	}
+	return validateText(p, s)
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func validateText(p Parameter, s string) Errors {
+	var errs Errors
+	label := parameterLabel(p)
+	n := utf8.RuneCountInString(s)
+	if min, ok := toInt64(p.MinLength); ok && int64(n) < min {
+		errs = append(errs, parameterError(p, ErrorCodeMinLength, "%s must be at least %d characters long", label, min))
+	}
+	if max, ok := toInt64(p.MaxLength); ok && int64(n) > max {
+		errs = append(errs, parameterError(p, ErrorCodeMaxLength, "%s must be at most %d characters long", label, max))
+	}
+	if p.Pattern != "" {
+		// the pattern has to match the whole value
+		re, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+		switch {
+		case err != nil:
+			errs = append(errs, parameterError(p, ErrorCodePattern, "%s has an invalid pattern: %v", label, err))
+		case !re.MatchString(s):
+			errs = append(errs, parameterError(p, ErrorCodePattern, "%s does not match the required pattern", label))
+		}
+	}
+	return errs
+}
+
+func validateNumber(p Parameter, f float64) Errors {
+	var errs Errors
+	label := parameterLabel(p)
+	min, hasMin := toFloat64(p.Min)
+	if hasMin && f < min {
+		errs = append(errs, parameterError(p, ErrorCodeMin, "%s must be greater than or equal to %v", label, p.Min))
+	}
+	if max, ok := toFloat64(p.Max); ok && f > max {
+		errs = append(errs, parameterError(p, ErrorCodeMax, "%s must be less than or equal to %v", label, p.Max))
+	}
+	if step, ok := toFloat64(p.Step); ok && step > 0 {
+		base := 0.0
+		if hasMin {
+			base = min
+		}
+		n := (f - base) / step
+		if math.Abs(n-math.Round(n)) > 1e-9 {
+			errs = append(errs, parameterError(p, ErrorCodeStep, "%s must be a multiple of %v", label, p.Step))
+		}
+	}
+	return errs
+}
+
+func validateTime(p Parameter, t time.Time) Errors {
+	var errs Errors
+	label := parameterLabel(p)
+	if s, ok := p.Min.(string); ok {
+		if min, err := parseTimeValue(p.Type, s); err == nil && t.Before(min) {
+			errs = append(errs, parameterError(p, ErrorCodeMin, "%s must not be before %s", label, s))
+		}
+	}
+	if s, ok := p.Max.(string); ok {
+		if max, err := parseTimeValue(p.Type, s); err == nil && t.After(max) {
+			errs = append(errs, parameterError(p, ErrorCodeMax, "%s must not be after %s", label, s))
+		}
+	}
+	return errs
+}
+
+func hasOption(os SelectOptions, v interface{}) bool {
+	_, ok := findOption(os, v)
+	return ok
+}
+
+// findOption returns the option (of an option group) whose value formats like v.
+func findOption(os SelectOptions, v interface{}) (SelectOption, bool) {
+	want := fmt.Sprintf("%v", v)
+	for _, o := range os {
+		if len(o.Options) > 0 {
+			// option group
+			if so, ok := findOption(o.Options, v); ok {
+				return so, true
+			}
+			continue
+		}
+		if fmt.Sprintf("%v", o.Value) == want {
+			return o, true
+		}
+	}
+	return SelectOption{}, false
+}
+
+// multipleValues returns the elements of v if v is a slice.
+func multipleValues(v interface{}) ([]interface{}, bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		vs := make([]interface{}, len(v))
+		for i, s := range v {
+			vs[i] = s
+		}
+		return vs, true
+	case []*multipart.FileHeader:
+		vs := make([]interface{}, len(v))
+		for i, fh := range v {
+			vs[i] = fh
+		}
+		return vs, true
+	}
+	return nil, false
+}
+
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if s, ok := v.(string); ok {
+		return s == ""
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return false
+}
+
+func parameterLabel(p Parameter) string {
+	if p.Label != "" {
+		return p.Label
+	}
+	return p.Name
+}
+
+func parameterError(p Parameter, code string, format string, args ...interface{}) Error {
+	return Error{
+		Label:   p.Label,
+		Name:    p.Name,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,300 @@
+package hyper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Parameters
+		args   Arguments
+		codes  []string
+	}{
+		{
+			name:   "required missing",
+			params: Parameters{{Name: "a", Type: TypeText, Required: true}},
+			args:   Arguments{},
+			codes:  []string{ErrorCodeRequired},
+		},
+		{
+			name:   "required empty",
+			params: Parameters{{Name: "a", Type: TypeText, Required: true}},
+			args:   Arguments{"a": ""},
+			codes:  []string{ErrorCodeRequired},
+		},
+		{
+			name:   "optional empty",
+			params: Parameters{{Name: "a", Type: TypeNumber, Min: 3}},
+			args:   Arguments{"a": ""},
+		},
+		{
+			name:   "number from form",
+			params: Parameters{{Name: "a", Type: TypeNumber, Min: 1, Max: 10, Step: 0.5}},
+			args:   Arguments{"a": "2.5"},
+		},
+		{
+			name:   "number from json",
+			params: Parameters{{Name: "a", Type: TypeNumber, Min: 1, Max: 10}},
+			args:   Arguments{"a": 11.0},
+			codes:  []string{ErrorCodeMax},
+		},
+		{
+			name:   "number step",
+			params: Parameters{{Name: "a", Type: TypeNumber, Min: 1, Step: 2}},
+			args:   Arguments{"a": 4.0},
+			codes:  []string{ErrorCodeStep},
+		},
+		{
+			name:   "not a number",
+			params: Parameters{{Name: "a", Type: TypeNumber}},
+			args:   Arguments{"a": "abc"},
+			codes:  []string{ErrorCodeType},
+		},
+		{
+			name:   "integer",
+			params: Parameters{{Name: "a", Type: TypeInteger, Min: 0}},
+			args:   Arguments{"a": 1.5},
+			codes:  []string{ErrorCodeType},
+		},
+		{
+			name:   "text length and pattern",
+			params: Parameters{{Name: "a", Type: TypeText, MinLength: 3, MaxLength: 4, Pattern: "[a-z]+"}},
+			args:   Arguments{"a": "abcd1"},
+			codes:  []string{ErrorCodeMaxLength, ErrorCodePattern},
+		},
+		{
+			name:   "invalid pattern",
+			params: Parameters{{Name: "a", Type: TypeText, Pattern: "[a-z"}},
+			args:   Arguments{"a": "abc"},
+			codes:  []string{ErrorCodePattern},
+		},
+		{
+			name:   "integer overflow",
+			params: Parameters{{Name: "a", Type: TypeInteger}},
+			args:   Arguments{"a": float64(1 << 63)},
+			codes:  []string{ErrorCodeType},
+		},
+		{
+			name:   "email",
+			params: Parameters{{Name: "a", Type: TypeEmail}},
+			args:   Arguments{"a": "Foo <foo@example.com>"},
+			codes:  []string{ErrorCodeType},
+		},
+		{
+			name:   "url",
+			params: Parameters{{Name: "a", Type: TypeURL}, {Name: "b", Type: TypeURL}},
+			args:   Arguments{"a": "http://example.com/foo", "b": "/foo"},
+			codes:  []string{ErrorCodeType},
+		},
+		{
+			name:   "date",
+			params: Parameters{{Name: "a", Type: TypeDate, Min: "2020-01-01"}, {Name: "b", Type: TypeDate}},
+			args:   Arguments{"a": "2019-12-31", "b": "2019-13-01"},
+			codes:  []string{ErrorCodeMin, ErrorCodeType},
+		},
+		{
+			name:   "week",
+			params: Parameters{{Name: "a", Type: TypeWeek, Max: "2020-W10"}},
+			args:   Arguments{"a": "2020-W11"},
+			codes:  []string{ErrorCodeMax},
+		},
+		{
+			name: "select",
+			params: Parameters{{Name: "a", Type: TypeSelect, Options: SelectOptions{
+				{Label: "Group", Options: SelectOptions{{Value: "x"}, {Value: "y"}}},
+			}}},
+			args:  Arguments{"a": "z"},
+			codes: []string{ErrorCodeOption},
+		},
+		{
+			name:   "multiple not allowed",
+			params: Parameters{{Name: "a", Type: TypeText}},
+			args:   Arguments{"a": []string{"x", "y"}},
+			codes:  []string{ErrorCodeMultiple},
+		},
+		{
+			name:   "multiple",
+			params: Parameters{{Name: "a", Type: TypeInteger, Multiple: true, Max: 3}},
+			args:   Arguments{"a": []interface{}{1.0, 4.0}},
+			codes:  []string{ErrorCodeMax},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := MakeCommand()
+			c.Arguments = test.args
+			errs := Validate(Action{Parameters: test.params}, c)
+			var codes []string
+			for _, e := range errs {
+				if e.Name == "" {
+					t.Errorf("missing name: %#v", e)
+				}
+				codes = append(codes, e.Code)
+			}
+			if !reflect.DeepEqual(test.codes, codes) {
+				t.Errorf("want: %v, got: %v (%v)", test.codes, codes, errs)
+			}
+		})
+	}
+}
+
+func TestErrorItemErrors(t *testing.T) {
+	errs := Errors{{Name: "a", Code: ErrorCodeRequired, Message: "a is required"}}
+	i := ErrorItem(errs)
+	if !reflect.DeepEqual(errs, i.Errors) {
+		t.Errorf("want: %#v, got: %#v", errs, i.Errors)
+	}
+}
+
+func TestFilterConfigurationValidateGroups(t *testing.T) {
+	c := FilterConfiguration{{Name: "count", Type: TypeInteger}}
+	f := Filter{FilterOr(
+		FilterComponent{Name: "count", Operator: FilterOperatorEquals, Value: "1"},
+		FilterNot(FilterComponent{Name: "count", Operator: FilterOperatorEquals, Value: "x"}),
+	)}
+	tests := []struct {
+		name   string
+		groups []FilterOperator
+		codes  []string
+	}{
+		{name: "not advertised", codes: []string{ErrorCodeOption}},
+		{name: "nested not advertised", groups: []FilterOperator{FilterOperatorOr}, codes: []string{ErrorCodeOption}},
+		{name: "advertised", groups: []FilterOperator{FilterOperatorOr, FilterOperatorNot}, codes: []string{ErrorCodeType}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var codes []string
+			for _, e := range c.Validate(f, test.groups...) {
+				codes = append(codes, e.Code)
+			}
+			if !reflect.DeepEqual(test.codes, codes) {
+				t.Errorf("want: %v, got: %v", test.codes, codes)
+			}
+		})
+	}
+}
+
+func TestFilterConfigurationValidate(t *testing.T) {
+	c := FilterConfiguration{
+		{Name: "name", Type: TypeText, Operators: FilterOperatorConfigurationsForTextReduced()},
+		{Name: "count", Type: TypeInteger},
+		{Name: "created", Type: TypeDate},
+		{Name: "state", Type: TypeSelect, Multiple: true, Options: SelectOptions{{Value: "open"}, {Value: "closed"}}},
+		{Name: "kind", Type: TypeSelect, Options: SelectOptions{{Value: "a"}, {Value: "b"}}},
+	}
+	tests := []struct {
+		name   string
+		filter Filter
+		codes  []string
+	}{
+		{
+			name: "valid",
+			filter: Filter{
+				{Name: "name", Operator: FilterOperatorLike, Value: "%x%"},
+				{Name: "count", Operator: FilterOperatorBetween, Value: []string{"1", "10"}},
+				{Name: "created", Operator: FilterOperatorGreaterThenOrEquals, Value: "2020-01-01"},
+				{Name: "state", Operator: FilterOperatorIn, Value: []string{"open", "closed"}},
+				{Name: "kind", Operator: FilterOperatorIn, Value: []string{"a"}},
+			},
+		},
+		{
+			name: "typed",
+			filter: Filter{
+				{Name: "count", Operator: FilterOperatorIn, Value: []interface{}{int64(1), int64(2)}},
+				{Name: "created", Operator: FilterOperatorEquals, Value: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:   "unknown name",
+			filter: Filter{{Name: "secret", Operator: FilterOperatorEquals, Value: "x"}},
+			codes:  []string{ErrorCodeOption},
+		},
+		{
+			name:   "operator not configured",
+			filter: Filter{{Name: "name", Operator: FilterOperatorEquals, Value: "x"}},
+			codes:  []string{ErrorCodeOption},
+		},
+		{
+			name:   "operator not supported by type",
+			filter: Filter{{Name: "count", Operator: FilterOperatorLike, Value: "1"}},
+			codes:  []string{ErrorCodeOption},
+		},
+		{
+			name:   "unknown operator",
+			filter: Filter{{Name: "count", Operator: "~", Value: "1"}},
+			codes:  []string{ErrorCodeOption},
+		},
+		{
+			name: "type",
+			filter: Filter{
+				{Name: "count", Operator: FilterOperatorIn, Value: []string{"1", "x"}},
+				{Name: "created", Operator: FilterOperatorLessThen, Value: "yesterday"},
+			},
+			codes: []string{ErrorCodeType, ErrorCodeType},
+		},
+		{
+			name:   "option",
+			filter: Filter{{Name: "state", Operator: FilterOperatorEquals, Value: "lost"}},
+			codes:  []string{ErrorCodeOption},
+		},
+		{
+			name:   "multiple not allowed",
+			filter: Filter{{Name: "kind", Operator: FilterOperatorIn, Value: []string{"a", "b"}}},
+			codes:  []string{ErrorCodeMultiple},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := c.Validate(test.filter)
+			var codes []string
+			for _, e := range errs {
+				codes = append(codes, e.Code)
+			}
+			if !reflect.DeepEqual(test.codes, codes) {
+				t.Errorf("want: %v, got: %v (%v)", test.codes, codes, errs)
+			}
+		})
+	}
+}
+
+func TestSortConfigurationValidate(t *testing.T) {
+	c := SortConfiguration{
+		{Name: "name"},
+		{Name: "created", Orders: []SortOrderConfiguration{{Order: SortOrderDescending}}},
+	}
+	tests := []struct {
+		name  string
+		sort  Sort
+		codes []string
+	}{
+		{
+			name: "valid",
+			sort: Sort{{Name: "name", Order: SortOrderAscending}, {Name: "created", Order: SortOrderDescending}},
+		},
+		{
+			name:  "unknown name",
+			sort:  Sort{{Name: "secret", Order: SortOrderAscending}},
+			codes: []string{ErrorCodeOption},
+		},
+		{
+			name:  "order",
+			sort:  Sort{{Name: "created", Order: SortOrderAscending}},
+			codes: []string{ErrorCodeOption},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := c.Validate(test.sort)
+			var codes []string
+			for _, e := range errs {
+				codes = append(codes, e.Code)
+			}
+			if !reflect.DeepEqual(test.codes, codes) {
+				t.Errorf("want: %v, got: %v (%v)", test.codes, codes, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package hyper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts lists the accepted representations for the time based types.
+var timeLayouts = map[string][]string{
+	TypeDate:     {"2006-01-02"},
+	TypeDatetime: {"2006-01-02T15:04", "2006-01-02T15:04:05", time.RFC3339, time.RFC3339Nano},
+	TypeMonth:    {"2006-01"},
+	TypeTime:     {"15:04", "15:04:05", "15:04:05.999999999"},
+}
+
+func isTimeType(typ string) bool {
+	switch typ {
+	case TypeDate, TypeDatetime, TypeMonth, TypeWeek, TypeTime:
+		return true
+	}
+	return false
+}
+
+// isTextType reports whether values of the type are kept as text by typedValue.
+func isTextType(typ string) bool {
+	switch typ {
+	case TypeInteger, TypeNumber, TypeRange, TypeBool, TypeCheckbox:
+		return false
+	}
+	return !isTimeType(typ)
+}
+
+// parseTimeValue parses s according to the time based parameter type typ.
+func parseTimeValue(typ string, s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if typ == TypeWeek {
+		return parseWeek(s)
+	}
+	for _, layout := range timeLayouts[typ] {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s: %q", typ, s)
+}
+
+// parseWeek parses an ISO week of the form 2006-W02 and returns the monday of that week.
+func parseWeek(s string) (time.Time, error) {
+	parts := strings.Split(s, "-W")
+	if len(parts) != 2 || len(parts[0]) != 4 || len(parts[1]) != 2 {
+		return time.Time{}, fmt.Errorf("invalid %s: %q", TypeWeek, s)
+	}
+	year, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %q", TypeWeek, s)
+	}
+	week, err := strconv.Atoi(parts[1])
+	if err != nil || week < 1 || week > 53 {
+		return time.Time{}, fmt.Errorf("invalid %s: %q", TypeWeek, s)
+	}
+	// January 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	monday := jan4.AddDate(0, 0, -offset+(week-1)*7)
+	if y, _ := monday.ISOWeek(); y != year {
+		return time.Time{}, fmt.Errorf("invalid %s: %q", TypeWeek, s)
+	}
+	return monday, nil
+}
+
+func toString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, bool:
+		return fmt.Sprintf("%v", v), true
+	default:
+		return "", false
+	}
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case float64:
+		// float64(math.MaxInt64) rounds up to 1<<63, which does not fit
+		if v != math.Trunc(v) || v >= 1<<63 || v < math.MinInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	case float32:
+		return toInt64(float64(v))
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toBool(v interface{}) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "on":
+			return true, true
+		case "off":
+			return false, true
+		}
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	default:
+		if i, ok := toInt64(v); ok {
+			return i != 0, true
+		}
+		return false, false
+	}
+}
+
+// typedValue converts a value (usually a string from a query) into the Go type of a parameter type:
+// int64 for integers, float64 for numbers, bool for bools, time.Time for the time types and
+// string for everything else.
+func typedValue(typ string, v interface{}) (interface{}, error) {
+	switch {
+	case typ == TypeInteger:
+		if i, ok := toInt64(v); ok {
+			return i, nil
+		}
+		return nil, fmt.Errorf("invalid integer: %v", v)
+	case typ == TypeNumber || typ == TypeRange:
+		if f, ok := toFloat64(v); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("invalid number: %v", v)
+	case typ == TypeBool || typ == TypeCheckbox:
+		if b, ok := toBool(v); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("invalid bool: %v", v)
+	case isTimeType(typ):
+		if t, ok := v.(time.Time); ok {
+			return t, nil
+		}
+		s, _ := toString(v)
+		t, err := parseTimeValue(typ, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", typ, v)
+		}
+		return t, nil
+	}
+	if s, ok := toString(v); ok {
+		return s, nil
+	}
+	return fmt.Sprint(v), nil
+}